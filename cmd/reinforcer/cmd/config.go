@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go/build"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// defaultConfigFile is the project-level config file, it's looked up in the working directory
+const defaultConfigFile = "reinforcer.yaml"
+
+// config is the content of the config file, e.g.:
+//
+//	jobs:
+//	  - name: clients
+//	    src: [./client/client.go]
+//	    target: [Client, ".*Service"]
+//	    exclude: [LegacyService]
+//	    outputdir: ./client/reinforced
+//	    ignorenoret: true
+//	  - srcpkg: [github.com/csueiras/somelib]
+//	    targetall: true
+//	    outputdir: ./somelib/reinforced
+//	    runtime: native
+type config struct {
+	// Jobs are the generation jobs, all of them are executed by a single run
+	Jobs []*job `mapstructure:"jobs"`
+}
+
+// job is a generation job, its settings are named after the flags that override them
+type job struct {
+	Name                  string   `mapstructure:"name"`
+	Sources               []string `mapstructure:"src"`
+	SourcePackages        []string `mapstructure:"srcpkg"`
+	Targets               []string `mapstructure:"target"`
+	TargetsAll            bool     `mapstructure:"targetall"`
+	Excludes              []string `mapstructure:"exclude"`
+	OutPkg                string   `mapstructure:"outpkg"`
+	OutputDir             string   `mapstructure:"outputdir"`
+	IgnoreNoReturnMethods bool     `mapstructure:"ignorenoret"`
+	Runtime               string   `mapstructure:"runtime"`
+}
+
+// loadConfig reads the given config file, or the project-level config file if none is given. Nil is returned when no
+// file is given and there is no project-level config file. The relative paths of the jobs are resolved from the config
+// file's directory, including the relative source packages (e.g. ./client).
+func loadConfig(cfgFile string) (*config, error) {
+	if cfgFile == "" {
+		if _, err := os.Stat(defaultConfigFile); errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		cfgFile = defaultConfigFile
+	}
+	log.Debug().Msgf("Using config file %s", cfgFile)
+
+	v := viper.New()
+	v.SetConfigFile(cfgFile)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file %s; error=%w", cfgFile, err)
+	}
+	cfg := &config{}
+	if err := v.Unmarshal(cfg, func(c *mapstructure.DecoderConfig) {
+		// Misspelled settings would silently fall back to the flag's defaults
+		c.ErrorUnused = true
+	}); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s; error=%w", cfgFile, err)
+	}
+	if len(cfg.Jobs) == 0 {
+		return nil, fmt.Errorf("config file %s doesn't declare any jobs", cfgFile)
+	}
+
+	dir := filepath.Dir(cfgFile)
+	for _, j := range cfg.Jobs {
+		for i, src := range j.Sources {
+			j.Sources[i] = resolvePath(dir, src)
+		}
+		for i, pkg := range j.SourcePackages {
+			j.SourcePackages[i] = resolvePackage(dir, pkg)
+		}
+		if j.OutputDir != "" {
+			j.OutputDir = resolvePath(dir, j.OutputDir)
+		}
+	}
+	return cfg, nil
+}
+
+// resolvePath resolves the given path from the given directory unless it's absolute
+func resolvePath(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// resolvePackage resolves the given package from the given directory when it's relative (e.g. ./client), keeping it
+// relative to the working directory so that it isn't mistaken for an import path
+func resolvePackage(dir, pkg string) string {
+	if !build.IsLocalImport(pkg) {
+		return pkg
+	}
+	p := resolvePath(dir, pkg)
+	if filepath.IsAbs(p) || build.IsLocalImport(filepath.ToSlash(p)) {
+		return p
+	}
+	return "." + string(filepath.Separator) + p
+}
+
+// label identifies the job in the errors, the job's index is used for the jobs without a name
+func (j *job) label(index int) string {
+	if j.Name != "" {
+		return j.Name
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
+
+// applyFlags sets the job's settings from the flags that were given, as well as from the flag's default for the settings
+// that the job doesn't set
+func (j *job) applyFlags(flags *pflag.FlagSet) error {
+	for _, f := range []struct {
+		name string
+		set  bool
+		get  func() error
+	}{
+		{name: "src", set: len(j.Sources) > 0, get: stringSlice(flags, "src", &j.Sources)},
+		{name: "srcpkg", set: len(j.SourcePackages) > 0, get: stringSlice(flags, "srcpkg", &j.SourcePackages)},
+		{name: "target", set: len(j.Targets) > 0, get: stringSlice(flags, "target", &j.Targets)},
+		{name: "targetall", set: j.TargetsAll, get: boolean(flags, "targetall", &j.TargetsAll)},
+		{name: "exclude", set: len(j.Excludes) > 0, get: stringSlice(flags, "exclude", &j.Excludes)},
+		{name: "outpkg", set: j.OutPkg != "", get: str(flags, "outpkg", &j.OutPkg)},
+		{name: "outputdir", set: j.OutputDir != "", get: str(flags, "outputdir", &j.OutputDir)},
+		{name: "ignorenoret", set: j.IgnoreNoReturnMethods, get: boolean(flags, "ignorenoret", &j.IgnoreNoReturnMethods)},
+		{name: "runtime", set: j.Runtime != "", get: str(flags, "runtime", &j.Runtime)},
+	} {
+		if f.set && !flags.Changed(f.name) {
+			continue
+		}
+		if err := f.get(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stringSlice(flags *pflag.FlagSet, name string, v *[]string) func() error {
+	return func() (err error) {
+		*v, err = flags.GetStringSlice(name)
+		return err
+	}
+}
+
+func boolean(flags *pflag.FlagSet, name string, v *bool) func() error {
+	return func() (err error) {
+		*v, err = flags.GetBool(name)
+		return err
+	}
+}
+
+func str(flags *pflag.FlagSet, name string, v *string) func() error {
+	return func() (err error) {
+		*v, err = flags.GetString(name)
+		return err
+	}
+}
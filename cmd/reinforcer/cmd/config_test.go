@@ -0,0 +1,226 @@
+package cmd_test
+
+import (
+	"bytes"
+	"github.com/csueiras/reinforcer/cmd/reinforcer/cmd"
+	"github.com/csueiras/reinforcer/cmd/reinforcer/cmd/mocks"
+	"github.com/csueiras/reinforcer/internal/generator"
+	"github.com/csueiras/reinforcer/internal/generator/executor"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testJobsConfig = `
+jobs:
+  - name: clients
+    src: [./client/client.go]
+    target: [Client, ".*Service"]
+    exclude: [LegacyService]
+    outputdir: ./client/reinforced
+    ignorenoret: true
+  - srcpkg: [github.com/csueiras/somelib, ./vendored/lib]
+    targetall: true
+    outpkg: resilient
+    outputdir: ./somelib/resilient
+    runtime: native
+`
+
+// writeConfig writes the given config into a new directory, returning the directory and the config file's path
+func writeConfig(t *testing.T, config string) (string, string) {
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, "reinforcer.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte(config), 0644))
+	return dir, cfgFile
+}
+
+func TestRootCommand_Config(t *testing.T) {
+	gen := &generator.Generated{}
+
+	t.Run("Jobs", func(t *testing.T) {
+		dir, cfgFile := writeConfig(t, testJobsConfig)
+
+		exec := &mocks.Executor{}
+		exec.On("Execute", &executor.Parameters{
+			Sources:               []string{filepath.Join(dir, "client/client.go")},
+			SourcePackages:        []string{},
+			Targets:               []string{"Client", ".*Service"},
+			TargetsAll:            false,
+			Excludes:              []string{"LegacyService"},
+			OutPkg:                "reinforced",
+			IgnoreNoReturnMethods: true,
+			Runtime:               generator.GoResilienceRuntime,
+		}).Return(gen, nil).Once()
+		exec.On("Execute", &executor.Parameters{
+			Sources:               []string{},
+			SourcePackages:        []string{"github.com/csueiras/somelib", filepath.Join(dir, "vendored/lib")},
+			Targets:               []string{},
+			TargetsAll:            true,
+			Excludes:              []string{},
+			OutPkg:                "resilient",
+			IgnoreNoReturnMethods: false,
+			Runtime:               generator.NativeRuntime,
+		}).Return(gen, nil).Once()
+		writ := &mocks.Writer{}
+		writ.On("Write", filepath.Join(dir, "client/reinforced"), gen).Return(nil).Once()
+		writ.On("Write", filepath.Join(dir, "somelib/resilient"), gen).Return(nil).Once()
+
+		c := cmd.NewRootCmd(exec, writ)
+		c.SetOut(bytes.NewBufferString(""))
+		c.SetArgs([]string{"--config=" + cfgFile})
+		require.NoError(t, c.Execute())
+		exec.AssertExpectations(t)
+		writ.AssertExpectations(t)
+	})
+
+	t.Run("Flags Override Jobs", func(t *testing.T) {
+		dir, cfgFile := writeConfig(t, testJobsConfig)
+
+		exec := &mocks.Executor{}
+		exec.On("Execute", &executor.Parameters{
+			Sources:               []string{filepath.Join(dir, "client/client.go")},
+			SourcePackages:        []string{},
+			Targets:               []string{"Client", ".*Service"},
+			TargetsAll:            false,
+			Excludes:              []string{"LegacyService"},
+			OutPkg:                "other",
+			IgnoreNoReturnMethods: false,
+			Runtime:               generator.NativeRuntime,
+		}).Return(gen, nil).Once()
+		exec.On("Execute", &executor.Parameters{
+			Sources:               []string{},
+			SourcePackages:        []string{"github.com/csueiras/somelib", filepath.Join(dir, "vendored/lib")},
+			Targets:               []string{},
+			TargetsAll:            true,
+			Excludes:              []string{},
+			OutPkg:                "other",
+			IgnoreNoReturnMethods: false,
+			Runtime:               generator.NativeRuntime,
+		}).Return(gen, nil).Once()
+		writ := &mocks.Writer{}
+		writ.On("Write", filepath.Join(dir, "client/reinforced"), gen).Return(nil).Once()
+		writ.On("Write", filepath.Join(dir, "somelib/resilient"), gen).Return(nil).Once()
+
+		c := cmd.NewRootCmd(exec, writ)
+		c.SetOut(bytes.NewBufferString(""))
+		c.SetArgs([]string{"--config=" + cfgFile, "--outpkg=other", "--ignorenoret=false", "--runtime=native"})
+		require.NoError(t, c.Execute())
+		exec.AssertExpectations(t)
+		writ.AssertExpectations(t)
+	})
+
+	t.Run("Project Config File", func(t *testing.T) {
+		dir, _ := writeConfig(t, "jobs:\n  - src: [service.go]\n    srcpkg: [./client, ../lib]\n    target: [Service]\n")
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dir))
+		defer func() {
+			require.NoError(t, os.Chdir(wd))
+		}()
+
+		exec := &mocks.Executor{}
+		exec.On("Execute", &executor.Parameters{
+			Sources:               []string{"service.go"},
+			SourcePackages:        []string{"./client", "../lib"},
+			Targets:               []string{"Service"},
+			TargetsAll:            false,
+			Excludes:              []string{},
+			OutPkg:                "reinforced",
+			IgnoreNoReturnMethods: false,
+			Runtime:               generator.GoResilienceRuntime,
+		}).Return(gen, nil).Once()
+		writ := &mocks.Writer{}
+		writ.On("Write", "./reinforced", gen).Return(nil).Once()
+
+		c := cmd.NewRootCmd(exec, writ)
+		c.SetOut(bytes.NewBufferString(""))
+		c.SetArgs([]string{})
+		require.NoError(t, c.Execute())
+		exec.AssertExpectations(t)
+		writ.AssertExpectations(t)
+	})
+
+	t.Run("Relative Config File", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+		cfgFile := filepath.Join("sub", "reinforcer.yaml")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, cfgFile), []byte("jobs:\n  - src: [service.go]\n    srcpkg: [./client, github.com/csueiras/somelib]\n    target: [Service]\n    outputdir: ./reinforced\n"), 0644))
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dir))
+		defer func() {
+			require.NoError(t, os.Chdir(wd))
+		}()
+
+		exec := &mocks.Executor{}
+		exec.On("Execute", &executor.Parameters{
+			Sources:               []string{filepath.Join("sub", "service.go")},
+			SourcePackages:        []string{"." + string(filepath.Separator) + filepath.Join("sub", "client"), "github.com/csueiras/somelib"},
+			Targets:               []string{"Service"},
+			TargetsAll:            false,
+			Excludes:              []string{},
+			OutPkg:                "reinforced",
+			IgnoreNoReturnMethods: false,
+			Runtime:               generator.GoResilienceRuntime,
+		}).Return(gen, nil).Once()
+		writ := &mocks.Writer{}
+		writ.On("Write", filepath.Join("sub", "reinforced"), gen).Return(nil).Once()
+
+		c := cmd.NewRootCmd(exec, writ)
+		c.SetOut(bytes.NewBufferString(""))
+		c.SetArgs([]string{"--config=" + cfgFile})
+		require.NoError(t, c.Execute())
+		exec.AssertExpectations(t)
+		writ.AssertExpectations(t)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			config  string
+			wantErr string
+		}{
+			{
+				name:    "No Jobs",
+				config:  "jobs: []\n",
+				wantErr: "config file %s doesn't declare any jobs",
+			},
+			{
+				name:    "Unknown Setting",
+				config:  "jobs:\n  - src: [service.go]\n    targets: [Service]\n",
+				wantErr: "failed to parse config file %s; error=1 error(s) decoding:\n\n* 'jobs[0]' has invalid keys: targets",
+			},
+			{
+				name:    "Job Without Targets",
+				config:  "jobs:\n  - src: [service.go]\n    target: [Service]\n  - src: [other.go]\n",
+				wantErr: "job #2: no targets provided",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, cfgFile := writeConfig(t, tt.config)
+				exec := &mocks.Executor{}
+				exec.On("Execute", &executor.Parameters{
+					Sources:               []string{filepath.Join(filepath.Dir(cfgFile), "service.go")},
+					SourcePackages:        []string{},
+					Targets:               []string{"Service"},
+					TargetsAll:            false,
+					Excludes:              []string{},
+					OutPkg:                "reinforced",
+					IgnoreNoReturnMethods: false,
+					Runtime:               generator.GoResilienceRuntime,
+				}).Return(gen, nil)
+				writ := &mocks.Writer{}
+				writ.On("Write", "./reinforced", gen).Return(nil)
+
+				c := cmd.NewRootCmd(exec, writ)
+				c.SetOut(bytes.NewBufferString(""))
+				c.SetErr(bytes.NewBufferString(""))
+				c.SetArgs([]string{"--config=" + cfgFile})
+				require.EqualError(t, c.Execute(), strings.Replace(tt.wantErr, "%s", cfgFile, 1))
+			})
+		}
+	})
+}
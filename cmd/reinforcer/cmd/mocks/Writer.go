@@ -4,6 +4,7 @@ package mocks
 
 import (
 	generator "github.com/csueiras/reinforcer/internal/generator"
+	filename "github.com/csueiras/reinforcer/internal/writer/filename"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -12,6 +13,22 @@ type Writer struct {
 	mock.Mock
 }
 
+// FileNameStrategy provides a mock function with given fields:
+func (_m *Writer) FileNameStrategy() filename.Strategy {
+	ret := _m.Called()
+
+	var r0 filename.Strategy
+	if rf, ok := ret.Get(0).(func() filename.Strategy); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(filename.Strategy)
+		}
+	}
+
+	return r0
+}
+
 // Write provides a mock function with given fields: outputDirectory, generated
 func (_m *Writer) Write(outputDirectory string, generated *generator.Generated) error {
 	ret := _m.Called(outputDirectory, generated)
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/csueiras/reinforcer/internal/generator"
+	"github.com/csueiras/reinforcer/internal/writer"
+	"github.com/csueiras/reinforcer/internal/writer/filename"
+	wio "github.com/csueiras/reinforcer/internal/writer/io"
+	"github.com/pmezard/go-difflib/difflib"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// File statuses reported by the dry run
+const (
+	fileCreated   = "create"
+	fileUpdated   = "update"
+	fileUnchanged = "unchanged"
+)
+
+// generatedFile is a file that would be written to the output directory
+type generatedFile struct {
+	path     string
+	contents []byte
+}
+
+// generateInMemory writes the generated code to memory naming the files with the given strategy, the files are returned
+// sorted by their path
+func generateInMemory(outDir string, gen *generator.Generated, fileNameStrategy filename.Strategy) ([]generatedFile, error) {
+	buffers := wio.NewBufferOutputProvider()
+	if err := writer.New(buffers, fileNameStrategy).Write(outDir, gen); err != nil {
+		return nil, err
+	}
+
+	files := make([]generatedFile, 0, len(buffers.Buffers))
+	for p, b := range buffers.Buffers {
+		files = append(files, generatedFile{path: p, contents: b.Bytes()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+	return files, nil
+}
+
+// fileStatus compares the generated file with the one on disk
+func fileStatus(file generatedFile) (string, error) {
+	existing, err := os.ReadFile(file.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fileCreated, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(existing, file.contents) {
+		return fileUnchanged, nil
+	}
+	return fileUpdated, nil
+}
+
+// printDryRun prints the files that would be written and whether each one would change
+func printDryRun(w io.Writer, files []generatedFile) error {
+	for _, file := range files {
+		status, err := fileStatus(file)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%-9s %s\n", status, file.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// staleFiles returns the paths of the files in the output directory that were generated by reinforcer but aren't
+// generated anymore (e.g. the files of the types removed or renamed), sorted by their path
+func staleFiles(outDir string, files []generatedFile) ([]string, error) {
+	entries, err := os.ReadDir(outDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	generated := make(map[string]bool, len(files))
+	for _, file := range files {
+		generated[file.path] = true
+	}
+	var stale []string
+	for _, entry := range entries {
+		// The paths are joined as done by the writer
+		p := path.Join(outDir, entry.Name())
+		if entry.IsDir() || path.Ext(p) != ".go" || generated[p] {
+			continue
+		}
+		contents, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		if generator.IsGenerated(contents) {
+			stale = append(stale, p)
+		}
+	}
+	return stale, nil
+}
+
+// printDiffs prints a unified diff of every generated file that differs from the one on disk, as well as of the stale
+// files of the output directory to be removed. The number of files that differ is returned.
+func printDiffs(w io.Writer, outDir string, files []generatedFile) (int, error) {
+	drifted := 0
+	for _, file := range files {
+		fromFile := file.path
+		existing, err := os.ReadFile(file.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			fromFile = "/dev/null"
+		} else if err != nil {
+			return drifted, err
+		}
+		if bytes.Equal(existing, file.contents) {
+			continue
+		}
+
+		drifted++
+		if err := printDiff(w, fromFile, file.path, existing, file.contents); err != nil {
+			return drifted, err
+		}
+	}
+
+	stale, err := staleFiles(outDir, files)
+	if err != nil {
+		return drifted, err
+	}
+	for _, p := range stale {
+		existing, err := os.ReadFile(p)
+		if err != nil {
+			return drifted, err
+		}
+
+		drifted++
+		if err := printDiff(w, p, "/dev/null", existing, nil); err != nil {
+			return drifted, err
+		}
+	}
+	return drifted, nil
+}
+
+// printDiff prints the unified diff between the given contents of the files
+func printDiff(w io.Writer, fromFile, toFile string, from, to []byte) error {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(from)),
+		B:        splitLines(string(to)),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, diff)
+	return err
+}
+
+// splitLines splits the given text into lines keeping their line endings, unlike difflib.SplitLines no empty line is
+// added after the last line ending
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// printFiles prints the contents of the files, each one preceded by a marker with its path
+func printFiles(w io.Writer, files []generatedFile) error {
+	for i, file := range files {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "// ==> %s <==\n", file.path); err != nil {
+			return err
+		}
+		if _, err := w.Write(file.contents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -30,22 +30,23 @@ import (
 	"github.com/csueiras/reinforcer/internal/generator/executor"
 	"github.com/csueiras/reinforcer/internal/loader"
 	"github.com/csueiras/reinforcer/internal/writer"
-	"github.com/mitchellh/go-homedir"
+	"github.com/csueiras/reinforcer/internal/writer/filename"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
 	"os"
 	"path"
 )
 
 // Version will be set in CI to the current released version
 var Version = "0.0.0"
-var cfgFile string
 
 // Writer describes the code generator writer
 type Writer interface {
 	Write(outputDirectory string, generated *generator.Generated) error
+	// FileNameStrategy returns the strategy naming the files of the generated types
+	FileNameStrategy() filename.Strategy
 }
 
 // Executor describes the code generator executor
@@ -89,71 +90,47 @@ such as circuit breaker, retries, timeouts, etc.
 				zerolog.SetGlobalLevel(zerolog.Disabled)
 			}
 
-			sources, err := flags.GetStringSlice("src")
+			mode, err := getOutputMode(flags)
 			if err != nil {
 				return err
 			}
-			sourcePackages, err := flags.GetStringSlice("srcpkg")
-			if err != nil {
-				return err
-			}
-			if len(sources)+len(sourcePackages) == 0 {
-				goFile := os.Getenv("GOFILE")
-				if goFile == "" {
-					return fmt.Errorf("no source provided")
-				}
 
-				defSrcFile, err := os.Getwd()
-				if err != nil {
-					return err
-				}
-				sources = append(sources, path.Join(defSrcFile, goFile))
-			}
-
-			targetAll, err := flags.GetBool("targetall")
-			if err != nil {
-				return err
-			}
-			targets, err := flags.GetStringSlice("target")
-			if err != nil {
-				return err
-			}
-			if len(targets) == 0 && !targetAll {
-				return fmt.Errorf("no targets provided")
-			}
-			outPkg, err := flags.GetString("outpkg")
-			if err != nil {
-				return err
-			}
-			outDir, err := flags.GetString("outputdir")
+			cfgFile, err := flags.GetString("config")
 			if err != nil {
 				return err
 			}
-			ignoreNoRet, err := flags.GetBool("ignorenoret")
+			cfg, err := loadConfig(cfgFile)
 			if err != nil {
 				return err
 			}
 
-			gen, err := exec.Execute(&executor.Parameters{
-				Sources:               sources,
-				SourcePackages:        sourcePackages,
-				Targets:               targets,
-				TargetsAll:            targetAll,
-				OutPkg:                outPkg,
-				IgnoreNoReturnMethods: ignoreNoRet,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to generate code; error=%w", err)
+			// Without a config file the flags describe the only job
+			jobs := []*job{{}}
+			if cfg != nil {
+				jobs = cfg.Jobs
 			}
-			if err := writ.Write(outDir, gen); err != nil {
-				return fmt.Errorf("failed to save generated code; error=%w", err)
+			drifted := 0
+			for i, j := range jobs {
+				n, err := runJob(cmd, exec, writ, j, mode)
+				if err != nil {
+					if cfg != nil {
+						return fmt.Errorf("job %s: %w", j.label(i), err)
+					}
+					return err
+				}
+				drifted += n
+			}
+			if drifted > 0 {
+				// The drift isn't a usage error
+				cmd.SilenceUsage = true
+				return fmt.Errorf("generated code is out of date, %d file(s) differ", drifted)
 			}
 			return nil
 		},
 	}
 
 	rootCmd.PersistentFlags().
-		StringVar(&cfgFile, "config", "", "config file (default is $HOME/.reinforcer.yaml)")
+		String("config", "", "config file declaring the generation jobs, the flags given override the settings of every job (default is ./reinforcer.yaml)")
 
 	flags := rootCmd.Flags()
 	flags.BoolP("version", "v", false, "show reinforcer's version")
@@ -163,48 +140,139 @@ such as circuit breaker, retries, timeouts, etc.
 	flags.StringSliceP("srcpkg", "k", nil, "source packages to scan for the target interface or struct.")
 	flags.StringSliceP("target", "t", []string{}, "name of target type or regex to match interface or struct names with")
 	flags.BoolP("targetall", "a", false, "codegen for all exported interfaces/structs discovered. This option is mutually exclusive with the target option.")
+	flags.StringSliceP("exclude", "x", nil, "name of type or regex to match interface or struct names to leave out of the targets")
 	flags.StringP("outputdir", "o", "./reinforced", "directory to write the generated code to")
 	flags.StringP("outpkg", "p", "reinforced", "name of generated package")
-	flags.BoolP("ignorenoret", "i", false, "ignores methods that don't return anything (they won't be wrapped in the middleware). By default they'll be wrapped in a middleware and if the middleware emits an error the call will panic.")
+	flags.BoolP("ignorenoret", "i", false, "ignores methods that don't return anything (they won't be wrapped in the middleware). By default they'll be wrapped in a middleware and if the middleware emits an error the call will panic, unless a handler is given with WithNoReturnErrorHandler.")
+	flags.Bool("check", false, "compares the generated code with the files in the output directory without writing them, prints a unified diff of the differences and fails if there are any")
+	flags.Bool("dry-run", false, "prints the files that would be written to the output directory and whether each one would change, without writing them")
+	flags.Bool("stdout", false, "prints the generated code to stdout instead of writing it to the output directory, each file is preceded by a marker with its path")
+	flags.String("runtime", string(generator.GoResilienceRuntime), "resilience runtime targeted by the generated code, either goresilience (github.com/slok/goresilience) or native (github.com/csueiras/reinforcer/pkg/resilience)")
 
 	return rootCmd
 }
 
-// Execute adds all child commands to the root command and sets flags appropriately.
-// This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() {
-	if err := DefaultRootCmd().Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+// outputMode determines what is done with the generated code
+type outputMode int
+
+const (
+	// writeMode writes the generated code to the output directory
+	writeMode outputMode = iota
+	// checkMode compares the generated code with the output directory
+	checkMode
+	// dryRunMode prints the files that would be written
+	dryRunMode
+	// stdoutMode prints the generated code
+	stdoutMode
+)
+
+// getOutputMode determines the output mode from the flags
+func getOutputMode(flags *pflag.FlagSet) (outputMode, error) {
+	check, err := flags.GetBool("check")
+	if err != nil {
+		return writeMode, err
+	}
+	dryRun, err := flags.GetBool("dry-run")
+	if err != nil {
+		return writeMode, err
+	}
+	toStdout, err := flags.GetBool("stdout")
+	if err != nil {
+		return writeMode, err
+	}
+	switch {
+	case countTrue(check, dryRun, toStdout) > 1:
+		return writeMode, fmt.Errorf("the check, dry-run and stdout options are mutually exclusive")
+	case check:
+		return checkMode, nil
+	case dryRun:
+		return dryRunMode, nil
+	case toStdout:
+		return stdoutMode, nil
+	default:
+		return writeMode, nil
 	}
 }
 
-func init() {
-	cobra.OnInitialize(initConfig)
-}
+// runJob generates the code of the given job, the flags given override the job's settings. The number of files that
+// differ from the output directory is returned in the check mode.
+func runJob(cmd *cobra.Command, exec Executor, writ Writer, j *job, mode outputMode) (int, error) {
+	if err := j.applyFlags(cmd.Flags()); err != nil {
+		return 0, err
+	}
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := homedir.Dir()
+	sources := j.Sources
+	if len(sources)+len(j.SourcePackages) == 0 {
+		goFile := os.Getenv("GOFILE")
+		if goFile == "" {
+			return 0, fmt.Errorf("no source provided")
+		}
+
+		defSrcFile, err := os.Getwd()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return 0, err
+		}
+		sources = append(sources, path.Join(defSrcFile, goFile))
+	}
+	if len(j.Targets) == 0 && !j.TargetsAll {
+		return 0, fmt.Errorf("no targets provided")
+	}
+
+	gen, err := exec.Execute(&executor.Parameters{
+		Sources:               sources,
+		SourcePackages:        j.SourcePackages,
+		Targets:               j.Targets,
+		TargetsAll:            j.TargetsAll,
+		Excludes:              j.Excludes,
+		OutPkg:                j.OutPkg,
+		IgnoreNoReturnMethods: j.IgnoreNoReturnMethods,
+		Runtime:               generator.Runtime(j.Runtime),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate code; error=%w", err)
+	}
+
+	if mode == writeMode {
+		if err := writ.Write(j.OutputDir, gen); err != nil {
+			return 0, fmt.Errorf("failed to save generated code; error=%w", err)
 		}
+		return 0, nil
+	}
 
-		// Search config in home directory with name ".reinforcer" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigName(".reinforcer")
+	files, err := generateInMemory(j.OutputDir, gen, writ.FileNameStrategy())
+	if err != nil {
+		return 0, fmt.Errorf("failed to save generated code; error=%w", err)
+	}
+	switch mode {
+	case checkMode:
+		drifted, err := printDiffs(cmd.OutOrStdout(), j.OutputDir, files)
+		if err != nil {
+			return 0, fmt.Errorf("failed to compare generated code; error=%w", err)
+		}
+		return drifted, nil
+	case dryRunMode:
+		return 0, printDryRun(cmd.OutOrStdout(), files)
+	default:
+		return 0, printFiles(cmd.OutOrStdout(), files)
 	}
+}
 
-	viper.AutomaticEnv() // read in environment variables that match
+// countTrue counts the given values that are true
+func countTrue(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := DefaultRootCmd().Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 }
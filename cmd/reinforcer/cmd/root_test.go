@@ -6,10 +6,30 @@ import (
 	"github.com/csueiras/reinforcer/cmd/reinforcer/cmd/mocks"
 	"github.com/csueiras/reinforcer/internal/generator"
 	"github.com/csueiras/reinforcer/internal/generator/executor"
+	"github.com/csueiras/reinforcer/internal/writer/filename"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
+// previewGen is the generated code used to test the modes that don't write to the output directory
+var previewGen = &generator.Generated{
+	Common:    "package reinforced\n\n// common\n",
+	Constants: "package reinforced\n\n// constants\n",
+	Files: []*generator.GeneratedFile{
+		{TypeName: "SomeClient", Contents: "package reinforced\n\n// SomeClient\n"},
+	},
+}
+
+// typeNameStrategy names the files after their type
+type typeNameStrategy struct{}
+
+func (typeNameStrategy) GenerateFileName(typeName string) string {
+	return typeName
+}
+
 func TestRootCommand(t *testing.T) {
 	gen := &generator.Generated{}
 
@@ -21,7 +41,9 @@ func TestRootCommand(t *testing.T) {
 			Targets:               []string{"Client", "SomeOtherClient"},
 			TargetsAll:            false,
 			OutPkg:                "reinforced",
+			Excludes:              []string{},
 			IgnoreNoReturnMethods: false,
+			Runtime:               generator.GoResilienceRuntime,
 		}).Return(gen, nil)
 		writ := &mocks.Writer{}
 		writ.On("Write", "./reinforced", gen).Return(nil)
@@ -41,7 +63,9 @@ func TestRootCommand(t *testing.T) {
 			Targets:               []string{"Client", "SomeOtherClient"},
 			TargetsAll:            false,
 			OutPkg:                "reinforced",
+			Excludes:              []string{},
 			IgnoreNoReturnMethods: false,
+			Runtime:               generator.GoResilienceRuntime,
 		}).Return(gen, nil)
 		writ := &mocks.Writer{}
 		writ.On("Write", "./reinforced", gen).Return(nil)
@@ -61,7 +85,9 @@ func TestRootCommand(t *testing.T) {
 			Targets:               []string{},
 			TargetsAll:            true,
 			OutPkg:                "reinforced",
+			Excludes:              []string{},
 			IgnoreNoReturnMethods: false,
+			Runtime:               generator.GoResilienceRuntime,
 		}).Return(gen, nil)
 		writ := &mocks.Writer{}
 		writ.On("Write", "./reinforced", gen).Return(nil)
@@ -81,7 +107,9 @@ func TestRootCommand(t *testing.T) {
 			Targets:               []string{"Client", "SomeOtherClient"},
 			TargetsAll:            false,
 			OutPkg:                "reinforced",
+			Excludes:              []string{},
 			IgnoreNoReturnMethods: true,
+			Runtime:               generator.GoResilienceRuntime,
 		}).Return(gen, nil)
 		writ := &mocks.Writer{}
 		writ.On("Write", "./reinforced", gen).Return(nil)
@@ -93,6 +121,28 @@ func TestRootCommand(t *testing.T) {
 		require.NoError(t, c.Execute())
 	})
 
+	t.Run("Native Runtime", func(t *testing.T) {
+		exec := &mocks.Executor{}
+		exec.On("Execute", &executor.Parameters{
+			Sources:               []string{"/path/to/target.go"},
+			SourcePackages:        []string{},
+			Targets:               []string{"Client"},
+			TargetsAll:            false,
+			OutPkg:                "reinforced",
+			Excludes:              []string{},
+			IgnoreNoReturnMethods: false,
+			Runtime:               generator.NativeRuntime,
+		}).Return(gen, nil)
+		writ := &mocks.Writer{}
+		writ.On("Write", "./reinforced", gen).Return(nil)
+
+		b := bytes.NewBufferString("")
+		c := cmd.NewRootCmd(exec, writ)
+		c.SetOut(b)
+		c.SetArgs([]string{"--src=/path/to/target.go", "--target=Client", "--outputdir=./reinforced", "--runtime=native"})
+		require.NoError(t, c.Execute())
+	})
+
 	t.Run("No targets found", func(t *testing.T) {
 		exec := &mocks.Executor{}
 		exec.On("Execute", &executor.Parameters{
@@ -101,7 +151,9 @@ func TestRootCommand(t *testing.T) {
 			Targets:               []string{},
 			TargetsAll:            true,
 			OutPkg:                "reinforced",
+			Excludes:              []string{},
 			IgnoreNoReturnMethods: false,
+			Runtime:               generator.GoResilienceRuntime,
 		}).Return(nil, executor.ErrNoTargetableTypesFound)
 		writ := &mocks.Writer{}
 		writ.On("Write", "./reinforced", gen).Return(nil)
@@ -112,4 +164,159 @@ func TestRootCommand(t *testing.T) {
 		c.SetArgs([]string{"--src=/path/to/target.go", "--targetall", "--outputdir=./reinforced"})
 		require.EqualError(t, c.Execute(), "failed to generate code; error=no targetable types were discovered")
 	})
+
+	t.Run("Dry Run", func(t *testing.T) {
+		outDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outDir, "reinforcer_common.go"), []byte(previewGen.Common), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(outDir, "some_client.go"), []byte("package reinforced\n"), 0644))
+
+		exec := &mocks.Executor{}
+		exec.On("Execute", &executor.Parameters{
+			Sources:               []string{"/path/to/target.go"},
+			SourcePackages:        []string{},
+			Targets:               []string{},
+			TargetsAll:            true,
+			OutPkg:                "reinforced",
+			Excludes:              []string{},
+			IgnoreNoReturnMethods: false,
+			Runtime:               generator.GoResilienceRuntime,
+		}).Return(previewGen, nil)
+		writ := &mocks.Writer{}
+		writ.On("FileNameStrategy").Return(filename.SnakeCaseStrategy())
+
+		b := bytes.NewBufferString("")
+		c := cmd.NewRootCmd(exec, writ)
+		c.SetOut(b)
+		c.SetArgs([]string{"--src=/path/to/target.go", "--targetall", "--outputdir=" + outDir, "--dry-run"})
+		require.NoError(t, c.Execute())
+		require.Equal(t, "unchanged "+filepath.Join(outDir, "reinforcer_common.go")+"\n"+
+			"create    "+filepath.Join(outDir, "reinforcer_constants.go")+"\n"+
+			"update    "+filepath.Join(outDir, "some_client.go")+"\n", b.String())
+		writ.AssertNotCalled(t, "Write")
+
+		_, err := os.Stat(filepath.Join(outDir, "reinforcer_constants.go"))
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Stdout", func(t *testing.T) {
+		exec := &mocks.Executor{}
+		exec.On("Execute", &executor.Parameters{
+			Sources:               []string{"/path/to/target.go"},
+			SourcePackages:        []string{},
+			Targets:               []string{},
+			TargetsAll:            true,
+			OutPkg:                "reinforced",
+			Excludes:              []string{},
+			IgnoreNoReturnMethods: false,
+			Runtime:               generator.GoResilienceRuntime,
+		}).Return(previewGen, nil)
+		writ := &mocks.Writer{}
+		writ.On("FileNameStrategy").Return(filename.SnakeCaseStrategy())
+
+		b := bytes.NewBufferString("")
+		c := cmd.NewRootCmd(exec, writ)
+		c.SetOut(b)
+		c.SetArgs([]string{"--src=/path/to/target.go", "--targetall", "--outputdir=./reinforced", "--stdout"})
+		require.NoError(t, c.Execute())
+		require.Equal(t, `// ==> reinforced/reinforcer_common.go <==
+package reinforced
+
+// common
+
+// ==> reinforced/reinforcer_constants.go <==
+package reinforced
+
+// constants
+
+// ==> reinforced/some_client.go <==
+package reinforced
+
+// SomeClient
+`, b.String())
+		writ.AssertNotCalled(t, "Write")
+	})
+
+	t.Run("Dry Run And Stdout", func(t *testing.T) {
+		c := cmd.NewRootCmd(&mocks.Executor{}, &mocks.Writer{})
+		c.SetOut(bytes.NewBufferString(""))
+		c.SetArgs([]string{"--src=/path/to/target.go", "--targetall", "--dry-run", "--stdout"})
+		require.EqualError(t, c.Execute(), "the check, dry-run and stdout options are mutually exclusive")
+	})
+
+	t.Run("Check", func(t *testing.T) {
+		newCmd := func(outDir string, fileNameStrategy filename.Strategy) (*cobra.Command, *bytes.Buffer) {
+			exec := &mocks.Executor{}
+			exec.On("Execute", &executor.Parameters{
+				Sources:               []string{"/path/to/target.go"},
+				SourcePackages:        []string{},
+				Targets:               []string{},
+				TargetsAll:            true,
+				OutPkg:                "reinforced",
+				Excludes:              []string{},
+				IgnoreNoReturnMethods: false,
+				Runtime:               generator.GoResilienceRuntime,
+			}).Return(previewGen, nil)
+
+			writ := &mocks.Writer{}
+			writ.On("FileNameStrategy").Return(fileNameStrategy)
+
+			b := bytes.NewBufferString("")
+			c := cmd.NewRootCmd(exec, writ)
+			c.SetOut(b)
+			c.SetErr(bytes.NewBufferString(""))
+			c.SetArgs([]string{"--src=/path/to/target.go", "--targetall", "--outputdir=" + outDir, "--check"})
+			return c, b
+		}
+
+		outDir := t.TempDir()
+		common := filepath.Join(outDir, "reinforcer_common.go")
+		constants := filepath.Join(outDir, "reinforcer_constants.go")
+		someClient := filepath.Join(outDir, "some_client.go")
+		require.NoError(t, os.WriteFile(common, []byte(previewGen.Common), 0644))
+		require.NoError(t, os.WriteFile(someClient, []byte("package reinforced\n\n// SomeOldClient\n"), 0644))
+
+		c, b := newCmd(outDir, filename.SnakeCaseStrategy())
+		require.EqualError(t, c.Execute(), "generated code is out of date, 2 file(s) differ")
+		require.Equal(t, "--- /dev/null\n"+
+			"+++ "+constants+"\n"+
+			"@@ -0,0 +1,3 @@\n"+
+			"+package reinforced\n"+
+			"+\n"+
+			"+// constants\n"+
+			"--- "+someClient+"\n"+
+			"+++ "+someClient+"\n"+
+			"@@ -1,3 +1,3 @@\n"+
+			" package reinforced\n"+
+			" \n"+
+			"-// SomeOldClient\n"+
+			"+// SomeClient\n", b.String())
+		_, err := os.Stat(constants)
+		require.True(t, os.IsNotExist(err), "check must not write the generated code")
+
+		require.NoError(t, os.WriteFile(constants, []byte(previewGen.Constants), 0644))
+		require.NoError(t, os.WriteFile(someClient, []byte(previewGen.Files[0].Contents), 0644))
+		c, b = newCmd(outDir, filename.SnakeCaseStrategy())
+		require.NoError(t, c.Execute())
+		require.Empty(t, b.String())
+
+		// The files generated for the types that aren't generated anymore are reported, unlike the other files
+		removedClient := filepath.Join(outDir, "removed_client.go")
+		require.NoError(t, os.WriteFile(removedClient, []byte("// Code generated by reinforcer, DO NOT EDIT.\n\npackage reinforced\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(outDir, "helpers.go"), []byte("package reinforced\n"), 0644))
+		c, b = newCmd(outDir, filename.SnakeCaseStrategy())
+		require.EqualError(t, c.Execute(), "generated code is out of date, 1 file(s) differ")
+		require.Equal(t, "--- "+removedClient+"\n"+
+			"+++ /dev/null\n"+
+			"@@ -1,3 +0,0 @@\n"+
+			"-// Code generated by reinforcer, DO NOT EDIT.\n"+
+			"-\n"+
+			"-package reinforced\n", b.String())
+		require.NoError(t, os.Remove(removedClient))
+
+		// The files are named by the writer's strategy
+		require.NoError(t, os.Rename(someClient, filepath.Join(outDir, "SomeClient.go")))
+		c, b = newCmd(outDir, typeNameStrategy{})
+		require.NoError(t, c.Execute())
+		require.Empty(t, b.String())
+	})
 }
@@ -2,15 +2,23 @@
 
 package reinforced
 
-import "context"
+import (
+	"context"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
+)
 
 type targetClient interface {
-	GenerateGreeting(ctx context.Context, arg1 string) (string, error)
-	SayHello(ctx context.Context, arg1 string) error
+	GenerateGreeting(ctx context.Context, name string) (string, error)
+	SayHello(ctx context.Context, name string) error
 }
 type Client struct {
 	*base
 	delegate targetClient
+	runners  struct {
+		GenerateGreeting goresilience.Runner
+		SayHello         goresilience.Runner
+	}
 }
 
 func NewClient(delegate targetClient, runnerFactory runnerFactory, options ...Option) *Client {
@@ -22,46 +30,139 @@ func NewClient(delegate targetClient, runnerFactory runnerFactory, options ...Op
 	}
 	c := &Client{
 		base: &base{
-			errorPredicate: RetryAllErrors,
-			runnerFactory:  runnerFactory,
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
 		},
 		delegate: delegate,
 	}
 	for _, o := range options {
 		o(c.base)
 	}
+	c.runners.GenerateGreeting = c.runner(ClientMethods.GenerateGreeting)
+	c.runners.SayHello = c.runner(ClientMethods.SayHello)
 	return c
 }
-func (c *Client) GenerateGreeting(ctx context.Context, arg1 string) (string, error) {
+func WithClientGenerateGreetingResultPredicate(fn func(string, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[ClientMethods.GenerateGreeting] = fn
+	}
+}
+func WithClientGenerateGreetingFallback(fn func(ctx context.Context, name string, err error) (string, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[ClientMethods.GenerateGreeting] = fn
+	}
+}
+func WithClientSayHelloFallback(fn func(ctx context.Context, name string, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[ClientMethods.SayHello] = fn
+	}
+}
+func WithClientFallbackDelegate(fallback targetClient) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[ClientMethods.GenerateGreeting]; !ok {
+			o.fallbacks[ClientMethods.GenerateGreeting] = func(ctx context.Context, name string, _ error) (string, error) {
+				return fallback.GenerateGreeting(ctx, name)
+			}
+		}
+		if _, ok := o.fallbacks[ClientMethods.SayHello]; !ok {
+			o.fallbacks[ClientMethods.SayHello] = func(ctx context.Context, name string, _ error) error {
+				return fallback.SayHello(ctx, name)
+			}
+		}
+	}
+}
+func (c *Client) GenerateGreeting(ctx context.Context, name string) (string, error) {
 	var nonRetryableErr error
 	var r0 string
-	err := c.run(ctx, ClientMethods.GenerateGreeting, func(ctx context.Context) error {
-		var err error
-		r0, err = c.delegate.GenerateGreeting(ctx, arg1)
-		if c.errorPredicate(ClientMethods.GenerateGreeting, err) {
+	type results struct {
+		attempt int64
+		r0      string
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := c.runners.GenerateGreeting.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := c.delegate.GenerateGreeting(ctx, name)
+		if p, ok := c.resultPredicates[ClientMethods.GenerateGreeting].(func(string, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		if err != nil && c.shouldRetry(ClientMethods.GenerateGreeting, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return r0, nonRetryableErr
+	if err != nil {
+		if fallback, _ := c.fallbacks[ClientMethods.GenerateGreeting].(func(ctx context.Context, name string, err error) (string, error)); fallback != nil {
+			return fallback(ctx, name, err)
+		}
+		return *new(string), err
 	}
-	return r0, err
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
+	}
+	return r0, nonRetryableErr
 }
-func (c *Client) SayHello(ctx context.Context, arg1 string) error {
+func (c *Client) SayHello(ctx context.Context, name string) error {
 	var nonRetryableErr error
-	err := c.run(ctx, ClientMethods.SayHello, func(ctx context.Context) error {
-		var err error
-		err = c.delegate.SayHello(ctx, arg1)
-		if c.errorPredicate(ClientMethods.SayHello, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := c.runners.SayHello.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := c.delegate.SayHello(ctx, name)
+		if err != nil && c.shouldRetry(ClientMethods.SayHello, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := c.fallbacks[ClientMethods.SayHello].(func(ctx context.Context, name string, err error) error); fallback != nil {
+			return fallback(ctx, name, err)
+		}
+		return err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
 	}
-	return err
+	return nonRetryableErr
 }
@@ -3,13 +3,18 @@
 package reinforced
 
 import (
-	"context"
+	"errors"
 	goresilience "github.com/slok/goresilience"
 )
 
 type base struct {
-	errorPredicate func(string, error) bool
-	runnerFactory  runnerFactory
+	errorPredicate        func(string, error) bool
+	methodErrorPredicates map[string]func(error) bool
+	resultPredicates      map[string]interface{}
+	fallbacks             map[string]interface{}
+	noReturnErrorHandler  func(string, error)
+	runnerFactory         runnerFactory
+	instanceName          string
 }
 type runnerFactory interface {
 	GetRunner(name string) goresilience.Runner
@@ -18,6 +23,12 @@ type runnerFactory interface {
 var RetryAllErrors = func(_ string, _ error) bool {
 	return true
 }
+var PanicOnNoReturnError = func(_ string, err error) {
+	panic(err)
+}
+
+// ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate
+var ErrRetryableResult = errors.New("retryable result")
 
 type Option func(*base)
 
@@ -26,6 +37,33 @@ func WithRetryableErrorPredicate(fn func(string, error) bool) Option {
 		o.errorPredicate = fn
 	}
 }
-func (b *base) run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
-	return b.runnerFactory.GetRunner(name).Run(ctx, fn)
+func WithInstanceName(name string) Option {
+	return func(o *base) {
+		o.instanceName = name
+	}
+}
+func WithNoReturnErrorHandler(fn func(method string, err error)) Option {
+	return func(o *base) {
+		o.noReturnErrorHandler = fn
+	}
+}
+func WithMethodErrorPredicate(method string, fn func(error) bool) Option {
+	return func(o *base) {
+		if o.methodErrorPredicates == nil {
+			o.methodErrorPredicates = make(map[string]func(error) bool)
+		}
+		o.methodErrorPredicates[method] = fn
+	}
+}
+func (b *base) shouldRetry(method string, err error) bool {
+	if fn, ok := b.methodErrorPredicates[method]; ok {
+		return fn(err)
+	}
+	return b.errorPredicate(method, err)
+}
+func (b *base) runner(name string) goresilience.Runner {
+	if b.instanceName != "" {
+		name = b.instanceName + "." + name
+	}
+	return b.runnerFactory.GetRunner(name)
 }
@@ -2,33 +2,57 @@
 
 package reinforced
 
-// ClientMethods are the methods in Client
-var ClientMethods = struct {
+// ClientMethods are the methods in Client, these are the names of the runners used by each method
+var ClientMethods = clientMethods{
+	GenerateGreeting: "Client.GenerateGreeting",
+	SayHello:         "Client.SayHello",
+}
+
+// clientMethods holds the names of the methods in Client
+type clientMethods struct {
 	GenerateGreeting string
 	SayHello         string
-}{
-	GenerateGreeting: "GenerateGreeting",
-	SayHello:         "SayHello",
 }
 
-// ServiceMethods are the methods in Service
-var ServiceMethods = struct {
+// All returns the names of all the methods in Client
+func (m clientMethods) All() []string {
+	return []string{m.GenerateGreeting, m.SayHello}
+}
+
+// ServiceMethods are the methods in Service, these are the names of the runners used by each method
+var ServiceMethods = serviceMethods{
+	GetData: "Service.GetData",
+}
+
+// serviceMethods holds the names of the methods in Service
+type serviceMethods struct {
 	GetData string
-}{
-	GetData: "GetData",
 }
 
-// SomeOtherClientMethods are the methods in SomeOtherClient
-var SomeOtherClientMethods = struct {
+// All returns the names of all the methods in Service
+func (m serviceMethods) All() []string {
+	return []string{m.GetData}
+}
+
+// SomeOtherClientMethods are the methods in SomeOtherClient, these are the names of the runners used by each method
+var SomeOtherClientMethods = someOtherClientMethods{
+	DoStuff:            "SomeOtherClient.DoStuff",
+	GetUser:            "SomeOtherClient.GetUser",
+	MethodWithChannel:  "SomeOtherClient.MethodWithChannel",
+	MethodWithWildcard: "SomeOtherClient.MethodWithWildcard",
+	SaveFile:           "SomeOtherClient.SaveFile",
+}
+
+// someOtherClientMethods holds the names of the methods in SomeOtherClient
+type someOtherClientMethods struct {
 	DoStuff            string
 	GetUser            string
 	MethodWithChannel  string
 	MethodWithWildcard string
 	SaveFile           string
-}{
-	DoStuff:            "DoStuff",
-	GetUser:            "GetUser",
-	MethodWithChannel:  "MethodWithChannel",
-	MethodWithWildcard: "MethodWithWildcard",
-	SaveFile:           "SaveFile",
+}
+
+// All returns the names of all the methods in SomeOtherClient
+func (m someOtherClientMethods) All() []string {
+	return []string{m.DoStuff, m.GetUser, m.MethodWithChannel, m.MethodWithWildcard, m.SaveFile}
 }
@@ -2,7 +2,11 @@
 
 package reinforced
 
-import "context"
+import (
+	"context"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
+)
 
 type targetService interface {
 	GetData() ([]byte, error)
@@ -10,6 +14,9 @@ type targetService interface {
 type Service struct {
 	*base
 	delegate targetService
+	runners  struct {
+		GetData goresilience.Runner
+	}
 }
 
 func NewService(delegate targetService, runnerFactory runnerFactory, options ...Option) *Service {
@@ -21,30 +28,88 @@ func NewService(delegate targetService, runnerFactory runnerFactory, options ...
 	}
 	c := &Service{
 		base: &base{
-			errorPredicate: RetryAllErrors,
-			runnerFactory:  runnerFactory,
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
 		},
 		delegate: delegate,
 	}
 	for _, o := range options {
 		o(c.base)
 	}
+	c.runners.GetData = c.runner(ServiceMethods.GetData)
 	return c
 }
+func WithServiceGetDataResultPredicate(fn func([]byte, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[ServiceMethods.GetData] = fn
+	}
+}
+func WithServiceGetDataFallback(fn func(err error) ([]byte, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[ServiceMethods.GetData] = fn
+	}
+}
+func WithServiceFallbackDelegate(fallback targetService) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[ServiceMethods.GetData]; !ok {
+			o.fallbacks[ServiceMethods.GetData] = func(_ error) ([]byte, error) {
+				return fallback.GetData()
+			}
+		}
+	}
+}
 func (s *Service) GetData() ([]byte, error) {
 	var nonRetryableErr error
 	var r0 []byte
-	err := s.run(context.Background(), ServiceMethods.GetData, func(_ context.Context) error {
-		var err error
-		r0, err = s.delegate.GetData()
-		if s.errorPredicate(ServiceMethods.GetData, err) {
+	type results struct {
+		attempt int64
+		r0      []byte
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := s.runners.GetData.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := s.delegate.GetData()
+		if p, ok := s.resultPredicates[ServiceMethods.GetData].(func([]byte, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		if err != nil && s.shouldRetry(ServiceMethods.GetData, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return r0, nonRetryableErr
+	if err != nil {
+		if fallback, _ := s.fallbacks[ServiceMethods.GetData].(func(err error) ([]byte, error)); fallback != nil {
+			return fallback(err)
+		}
+		return *new([]byte), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
 	}
-	return r0, err
+	return r0, nonRetryableErr
 }
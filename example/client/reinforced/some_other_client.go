@@ -6,19 +6,28 @@ import (
 	"context"
 	client "github.com/csueiras/reinforcer/example/client"
 	sub "github.com/csueiras/reinforcer/example/client/sub"
+	goresilience "github.com/slok/goresilience"
 	"os"
+	"sync/atomic"
 )
 
 type targetSomeOtherClient interface {
 	DoStuff() error
 	GetUser(ctx context.Context) (*sub.User, error)
-	MethodWithChannel(arg0 <-chan bool) error
-	MethodWithWildcard(arg0 interface{})
-	SaveFile(arg0 *client.File, arg1 *os.File) error
+	MethodWithChannel(myChan <-chan bool) error
+	MethodWithWildcard(arg interface{})
+	SaveFile(myFile *client.File, osFile *os.File) error
 }
 type SomeOtherClient struct {
 	*base
 	delegate targetSomeOtherClient
+	runners  struct {
+		DoStuff            goresilience.Runner
+		GetUser            goresilience.Runner
+		MethodWithChannel  goresilience.Runner
+		MethodWithWildcard goresilience.Runner
+		SaveFile           goresilience.Runner
+	}
 }
 
 func NewSomeOtherClient(delegate targetSomeOtherClient, runnerFactory runnerFactory, options ...Option) *SomeOtherClient {
@@ -30,87 +39,251 @@ func NewSomeOtherClient(delegate targetSomeOtherClient, runnerFactory runnerFact
 	}
 	c := &SomeOtherClient{
 		base: &base{
-			errorPredicate: RetryAllErrors,
-			runnerFactory:  runnerFactory,
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
 		},
 		delegate: delegate,
 	}
 	for _, o := range options {
 		o(c.base)
 	}
+	c.runners.DoStuff = c.runner(SomeOtherClientMethods.DoStuff)
+	c.runners.GetUser = c.runner(SomeOtherClientMethods.GetUser)
+	c.runners.MethodWithChannel = c.runner(SomeOtherClientMethods.MethodWithChannel)
+	c.runners.MethodWithWildcard = c.runner(SomeOtherClientMethods.MethodWithWildcard)
+	c.runners.SaveFile = c.runner(SomeOtherClientMethods.SaveFile)
 	return c
 }
+func WithSomeOtherClientDoStuffFallback(fn func(err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[SomeOtherClientMethods.DoStuff] = fn
+	}
+}
+func WithSomeOtherClientGetUserResultPredicate(fn func(*sub.User, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[SomeOtherClientMethods.GetUser] = fn
+	}
+}
+func WithSomeOtherClientGetUserFallback(fn func(ctx context.Context, err error) (*sub.User, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[SomeOtherClientMethods.GetUser] = fn
+	}
+}
+func WithSomeOtherClientMethodWithChannelFallback(fn func(myChan <-chan bool, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[SomeOtherClientMethods.MethodWithChannel] = fn
+	}
+}
+func WithSomeOtherClientSaveFileFallback(fn func(myFile *client.File, osFile *os.File, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[SomeOtherClientMethods.SaveFile] = fn
+	}
+}
+func WithSomeOtherClientFallbackDelegate(fallback targetSomeOtherClient) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[SomeOtherClientMethods.DoStuff]; !ok {
+			o.fallbacks[SomeOtherClientMethods.DoStuff] = func(_ error) error {
+				return fallback.DoStuff()
+			}
+		}
+		if _, ok := o.fallbacks[SomeOtherClientMethods.GetUser]; !ok {
+			o.fallbacks[SomeOtherClientMethods.GetUser] = func(ctx context.Context, _ error) (*sub.User, error) {
+				return fallback.GetUser(ctx)
+			}
+		}
+		if _, ok := o.fallbacks[SomeOtherClientMethods.MethodWithChannel]; !ok {
+			o.fallbacks[SomeOtherClientMethods.MethodWithChannel] = func(myChan <-chan bool, _ error) error {
+				return fallback.MethodWithChannel(myChan)
+			}
+		}
+		if _, ok := o.fallbacks[SomeOtherClientMethods.SaveFile]; !ok {
+			o.fallbacks[SomeOtherClientMethods.SaveFile] = func(myFile *client.File, osFile *os.File, _ error) error {
+				return fallback.SaveFile(myFile, osFile)
+			}
+		}
+	}
+}
 func (s *SomeOtherClient) DoStuff() error {
 	var nonRetryableErr error
-	err := s.run(context.Background(), SomeOtherClientMethods.DoStuff, func(_ context.Context) error {
-		var err error
-		err = s.delegate.DoStuff()
-		if s.errorPredicate(SomeOtherClientMethods.DoStuff, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := s.runners.DoStuff.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := s.delegate.DoStuff()
+		if err != nil && s.shouldRetry(SomeOtherClientMethods.DoStuff, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := s.fallbacks[SomeOtherClientMethods.DoStuff].(func(err error) error); fallback != nil {
+			return fallback(err)
+		}
+		return err
 	}
-	return err
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
+	}
+	return nonRetryableErr
 }
 func (s *SomeOtherClient) GetUser(ctx context.Context) (*sub.User, error) {
 	var nonRetryableErr error
 	var r0 *sub.User
-	err := s.run(ctx, SomeOtherClientMethods.GetUser, func(ctx context.Context) error {
-		var err error
-		r0, err = s.delegate.GetUser(ctx)
-		if s.errorPredicate(SomeOtherClientMethods.GetUser, err) {
+	type results struct {
+		attempt int64
+		r0      *sub.User
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := s.runners.GetUser.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := s.delegate.GetUser(ctx)
+		if p, ok := s.resultPredicates[SomeOtherClientMethods.GetUser].(func(*sub.User, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		if err != nil && s.shouldRetry(SomeOtherClientMethods.GetUser, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return r0, nonRetryableErr
+	if err != nil {
+		if fallback, _ := s.fallbacks[SomeOtherClientMethods.GetUser].(func(ctx context.Context, err error) (*sub.User, error)); fallback != nil {
+			return fallback(ctx, err)
+		}
+		return *new(*sub.User), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
 	}
-	return r0, err
+	return r0, nonRetryableErr
 }
-func (s *SomeOtherClient) MethodWithChannel(arg0 <-chan bool) error {
+func (s *SomeOtherClient) MethodWithChannel(myChan <-chan bool) error {
 	var nonRetryableErr error
-	err := s.run(context.Background(), SomeOtherClientMethods.MethodWithChannel, func(_ context.Context) error {
-		var err error
-		err = s.delegate.MethodWithChannel(arg0)
-		if s.errorPredicate(SomeOtherClientMethods.MethodWithChannel, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := s.runners.MethodWithChannel.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := s.delegate.MethodWithChannel(myChan)
+		if err != nil && s.shouldRetry(SomeOtherClientMethods.MethodWithChannel, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := s.fallbacks[SomeOtherClientMethods.MethodWithChannel].(func(myChan <-chan bool, err error) error); fallback != nil {
+			return fallback(myChan, err)
+		}
+		return err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
 	}
-	return err
+	return nonRetryableErr
 }
-func (s *SomeOtherClient) MethodWithWildcard(arg0 interface{}) {
-	err := s.run(context.Background(), SomeOtherClientMethods.MethodWithWildcard, func(_ context.Context) error {
-		s.delegate.MethodWithWildcard(arg0)
+func (s *SomeOtherClient) MethodWithWildcard(arg interface{}) {
+	err := s.runners.MethodWithWildcard.Run(context.Background(), func(_ context.Context) error {
+		s.delegate.MethodWithWildcard(arg)
 		return nil
 	})
 	if err != nil {
-		panic(err)
+		s.noReturnErrorHandler(SomeOtherClientMethods.MethodWithWildcard, err)
 	}
 }
-func (s *SomeOtherClient) SaveFile(arg0 *client.File, arg1 *os.File) error {
+func (s *SomeOtherClient) SaveFile(myFile *client.File, osFile *os.File) error {
 	var nonRetryableErr error
-	err := s.run(context.Background(), SomeOtherClientMethods.SaveFile, func(_ context.Context) error {
-		var err error
-		err = s.delegate.SaveFile(arg0, arg1)
-		if s.errorPredicate(SomeOtherClientMethods.SaveFile, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := s.runners.SaveFile.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := s.delegate.SaveFile(myFile, osFile)
+		if err != nil && s.shouldRetry(SomeOtherClientMethods.SaveFile, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := s.fallbacks[SomeOtherClientMethods.SaveFile].(func(myFile *client.File, osFile *os.File, err error) error); fallback != nil {
+			return fallback(myFile, osFile, err)
+		}
+		return err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
 	}
-	return err
+	return nonRetryableErr
 }
@@ -7,6 +7,8 @@ import (
 	"github.com/csueiras/reinforcer/internal/generator"
 	"github.com/csueiras/reinforcer/internal/loader"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"regexp"
 )
 
 // ErrNoTargetableTypesFound indicates that no types that could be targeted for code generation were discovered
@@ -28,10 +30,14 @@ type Parameters struct {
 	Targets []string
 	// TargetsAll enables targeting of every exported interface type
 	TargetsAll bool
+	// Excludes contains the types to leave out of the targets, these are exact names or RegEx expressions
+	Excludes []string
 	// OutPkg the package name for the output code
 	OutPkg string
 	// IgnoreNoReturnMethods disables proxying of methods that don't return anything
 	IgnoreNoReturnMethods bool
+	// Runtime is the resilience runtime targeted by the generated code
+	Runtime generator.Runtime
 }
 
 // Executor is a utility service to orchestrate code generation
@@ -48,8 +54,12 @@ func New(l Loader) *Executor {
 func (e *Executor) Execute(settings *Parameters) (*generator.Generated, error) {
 	discoveredTypes := make(map[string]struct{})
 
+	excluded, err := excludeFilter(settings.Excludes)
+	if err != nil {
+		return nil, err
+	}
+
 	var cfg []*generator.FileConfig
-	var err error
 
 	for _, sourcePkg := range settings.SourcePackages {
 		var match map[string]*loader.Result
@@ -62,7 +72,7 @@ func (e *Executor) Execute(settings *Parameters) (*generator.Generated, error) {
 			return nil, errors.Wrapf(err, "failed to load from pkg=%s", sourcePkg)
 		}
 
-		configs, err := createFileConfigs(discoveredTypes, match)
+		configs, err := createFileConfigs(discoveredTypes, excluded, match)
 		if err != nil {
 			return nil, err
 		}
@@ -80,7 +90,7 @@ func (e *Executor) Execute(settings *Parameters) (*generator.Generated, error) {
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to load from file=%s", source)
 		}
-		configs, err := createFileConfigs(discoveredTypes, match)
+		configs, err := createFileConfigs(discoveredTypes, excluded, match)
 		if err != nil {
 			return nil, err
 		}
@@ -94,6 +104,7 @@ func (e *Executor) Execute(settings *Parameters) (*generator.Generated, error) {
 	code, err := generator.Generate(generator.Config{
 		OutPkg:                settings.OutPkg,
 		IgnoreNoReturnMethods: settings.IgnoreNoReturnMethods,
+		Runtime:               settings.Runtime,
 		Files:                 cfg,
 	})
 	if err != nil {
@@ -102,15 +113,48 @@ func (e *Executor) Execute(settings *Parameters) (*generator.Generated, error) {
 	return code, nil
 }
 
-func createFileConfigs(discoveredSet map[string]struct{}, match map[string]*loader.Result) ([]*generator.FileConfig, error) {
+// excludeFilter creates the filter of the excluded types, the expressions containing RegEx characters are matched as
+// RegEx while the rest must match the type's name exactly
+func excludeFilter(expressions []string) (func(typName string) bool, error) {
+	exact := make(map[string]struct{})
+	var exprs []*regexp.Regexp
+	for _, expr := range expressions {
+		if !loader.IsRegex(expr) {
+			exact[expr] = struct{}{}
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compile exclusion %q", expr)
+		}
+		exprs = append(exprs, re)
+	}
+	return func(typName string) bool {
+		if _, ok := exact[typName]; ok {
+			return true
+		}
+		for _, re := range exprs {
+			if re.MatchString(typName) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func createFileConfigs(discoveredSet map[string]struct{}, excluded func(typName string) bool, match map[string]*loader.Result) ([]*generator.FileConfig, error) {
 	var cfg []*generator.FileConfig
 	for typName, res := range match {
+		if excluded(typName) {
+			log.Debug().Msgf("Excluding type %s", typName)
+			continue
+		}
 		// Check types aren't repeated before adding them to the generator's config
 		if _, ok := discoveredSet[typName]; ok {
 			return nil, errors.Errorf("multiple types with same name discovered with name %s", typName)
 		}
 		discoveredSet[typName] = struct{}{}
-		cfg = append(cfg, generator.NewFileConfig(typName, typName, res.Methods))
+		cfg = append(cfg, generator.NewGenericFileConfig(typName, typName, res.TypeParams, res.Methods))
 	}
 	return cfg, nil
 }
@@ -59,6 +59,64 @@ func TestExecutor_Execute(t *testing.T) {
 		require.Equal(t, "LockService", got.Files[0].TypeName)
 	})
 
+	t.Run("Excludes types", func(t *testing.T) {
+		l := &mocks.Loader{}
+		l.On("LoadAll", "./testpkg.go", loader.FileLoadMode).Return(
+			map[string]*loader.Result{
+				"LockService":     {Name: "LockService", Methods: createTestServiceMethods()},
+				"MockLockService": {Name: "MockLockService", Methods: createTestServiceMethods()},
+				"LegacyService":   {Name: "LegacyService", Methods: createTestServiceMethods()},
+			}, nil,
+		)
+
+		exec := executor.New(l)
+		got, err := exec.Execute(&executor.Parameters{
+			Sources:    []string{"./testpkg.go"},
+			TargetsAll: true,
+			Excludes:   []string{"^Mock.*", "LegacyService"},
+			OutPkg:     "testpkg",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(got.Files))
+		require.Equal(t, "LockService", got.Files[0].TypeName)
+	})
+
+	t.Run("All types excluded", func(t *testing.T) {
+		l := &mocks.Loader{}
+		l.On("LoadMatched", "./testpkg.go", []string{"LockService"}, loader.FileLoadMode).Return(
+			map[string]*loader.Result{
+				"LockService": {Name: "LockService", Methods: createTestServiceMethods()},
+			}, nil,
+		)
+
+		exec := executor.New(l)
+		_, err := exec.Execute(&executor.Parameters{
+			Sources:  []string{"./testpkg.go"},
+			Targets:  []string{"LockService"},
+			Excludes: []string{"Lock"},
+			OutPkg:   "testpkg",
+		})
+		require.NoError(t, err, "exact exclusions must match the whole name")
+
+		_, err = exec.Execute(&executor.Parameters{
+			Sources:  []string{"./testpkg.go"},
+			Targets:  []string{"LockService"},
+			Excludes: []string{"Lock.*"},
+			OutPkg:   "testpkg",
+		})
+		require.EqualError(t, err, executor.ErrNoTargetableTypesFound.Error())
+	})
+
+	t.Run("Invalid exclusion", func(t *testing.T) {
+		exec := executor.New(&mocks.Loader{})
+		_, err := exec.Execute(&executor.Parameters{
+			Sources:  []string{"./testpkg.go"},
+			Excludes: []string{"Lock("},
+			OutPkg:   "testpkg",
+		})
+		require.Error(t, err)
+	})
+
 	t.Run("No types found", func(t *testing.T) {
 		l := &mocks.Loader{}
 		l.On("LoadMatched", "./testpkg.go", []string{"MyService"}, loader.FileLoadMode).
@@ -9,26 +9,61 @@ import (
 	"github.com/csueiras/reinforcer/internal/generator/retryable"
 	"github.com/dave/jennifer/jen"
 	"github.com/rs/zerolog/log"
+	"sort"
 	"strings"
 )
 
 var fileHeader = "Code generated by reinforcer, DO NOT EDIT."
 
+// IsGenerated determines whether the given file contents were generated by reinforcer
+func IsGenerated(contents []byte) bool {
+	return bytes.HasPrefix(contents, []byte("// "+fileHeader+"\n"))
+}
+
+// Runtime is the resilience runtime whose runners are used by the generated code
+type Runtime string
+
+const (
+	// GoResilienceRuntime targets the runners of github.com/slok/goresilience, this is the default runtime
+	GoResilienceRuntime Runtime = "goresilience"
+	// NativeRuntime targets the runners of reinforcer's own github.com/csueiras/reinforcer/pkg/resilience
+	NativeRuntime Runtime = "native"
+)
+
+// runnerPkg is the path of the package that declares the Runner used by the generated code
+func (r Runtime) runnerPkg() (string, error) {
+	switch r {
+	case "", GoResilienceRuntime:
+		return "github.com/slok/goresilience", nil
+	case NativeRuntime:
+		return "github.com/csueiras/reinforcer/pkg/resilience", nil
+	}
+	return "", fmt.Errorf("unknown runtime %q, must be one of %q or %q", r, GoResilienceRuntime, NativeRuntime)
+}
+
 // FileConfig holds the code generation configuration for a specific type
 type FileConfig struct {
 	// srcTypeName is the source type that we want to generate code for
 	srcTypeName string
 	// outTypeName is the desired output type name
 	outTypeName string
+	// typeParams are the type parameters declared by a generic source type
+	typeParams []*method.TypeParam
 	// methods that should be in the generated type
 	methods []*method.Method
 }
 
 // NewFileConfig creates a new instance of the FileConfig which holds code generation configuration
 func NewFileConfig(srcTypeName, outTypeName string, methods []*method.Method) *FileConfig {
+	return NewGenericFileConfig(srcTypeName, outTypeName, nil, methods)
+}
+
+// NewGenericFileConfig creates a new instance of the FileConfig for a generic type declaring the given type parameters
+func NewGenericFileConfig(srcTypeName, outTypeName string, typeParams []*method.TypeParam, methods []*method.Method) *FileConfig {
 	return &FileConfig{
 		srcTypeName: strings.Title(srcTypeName),
 		outTypeName: strings.Title(outTypeName),
+		typeParams:  typeParams,
 		methods:     methods,
 	}
 }
@@ -49,6 +84,8 @@ type Config struct {
 	Files []*FileConfig
 	// IgnoreNoReturnMethods determines whether methods that don't return anything should be wrapped in the middleware or not.
 	IgnoreNoReturnMethods bool
+	// Runtime is the resilience runtime targeted by the generated code, defaults to GoResilienceRuntime
+	Runtime Runtime
 }
 
 // GeneratedFile contains the code generation output for a specific type
@@ -84,7 +121,12 @@ func Generate(cfg Config) (*Generated, error) {
 		return nil, fmt.Errorf("must provide at least one file for generation")
 	}
 
-	c, err := generateCommon(cfg.OutPkg)
+	runnerPkg, err := cfg.Runtime.runnerPkg()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := generateCommon(cfg.OutPkg, runnerPkg)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +139,7 @@ func Generate(cfg Config) (*Generated, error) {
 
 	for _, fileConfig := range cfg.Files {
 		methods := fileConfig.methods
-		s, err := generateFile(cfg.OutPkg, cfg.IgnoreNoReturnMethods, fileConfig, methods)
+		s, err := generateFile(cfg.OutPkg, runnerPkg, cfg.IgnoreNoReturnMethods, fileConfig, methods)
 		if err != nil {
 			return nil, err
 		}
@@ -119,31 +161,57 @@ func Generate(cfg Config) (*Generated, error) {
 
 // generateFile generates the proxy code for the given interface, the interface must have at least one method returning an
 // error as those are the only ones wrapped in the middleware
-func generateFile(outPkg string, ignoreNoReturnMethods bool, fileCfg *FileConfig, methods []*method.Method) (string, error) {
+func generateFile(outPkg, runnerPkg string, ignoreNoReturnMethods bool, fileCfg *FileConfig, methods []*method.Method) (string, error) {
 	f := jen.NewFile(outPkg)
 	f.HeaderComment(fileHeader)
 
+	// Rename any parameters that would collide with the receiver of the proxy methods
+	reservedMethods := make([]*method.Method, 0, len(methods))
+	for _, meth := range methods {
+		reserved, err := meth.WithReservedNames(fileCfg.receiverName())
+		if err != nil {
+			return "", err
+		}
+		reservedMethods = append(reservedMethods, reserved)
+	}
+	methods = reservedMethods
+
 	// Declare the target interface we are proxying
 	var declMethods []jen.Code
 	for _, meth := range methods {
-		declMethods = append(declMethods, jen.Id(meth.Name).Params(meth.ParametersNameAndType...).Params(meth.ReturnTypes...))
+		declMethods = append(declMethods, jen.Id(meth.Name).Params(meth.ParametersNameAndType...).Params(meth.ResultsNameAndType...))
 	}
-	f.Add(jen.Type().Id(fileCfg.targetName()).Interface(
+	typeParamsDecl := method.TypeParamsDecl(fileCfg.typeParams)
+	typeParamsRef := method.TypeParamsRef(fileCfg.typeParams)
+	f.Add(jen.Type().Id(fileCfg.targetName()).Add(typeParamsDecl).Interface(
 		declMethods...,
 	))
 
+	// Resolve the runners of the methods wrapped in the middleware when the proxy is created, sparing the calls from
+	// looking them up in the factory
+	var runnerFields []jen.Code
+	var runnerAssign []jen.Code
+	for _, meth := range methods {
+		if !meth.ReturnsError && ignoreNoReturnMethods {
+			continue
+		}
+		runnerFields = append(runnerFields, jen.Id(meth.Name).Qual(runnerPkg, "Runner"))
+		// c.runners.Method = c.runner(TypeMethods.Method)
+		runnerAssign = append(runnerAssign, meth.RunnerRef("c").Op("=").Id("c").Dot("runner").Call(meth.ConstantRef(fileCfg.outTypeName)))
+	}
+
 	// Declare the proxy implementation
-	f.Add(jen.Type().Id(fileCfg.outTypeName).Struct(
+	proxyFields := []jen.Code{
 		jen.Op("*").Id("base"),
-		jen.Id("delegate").Id(fileCfg.targetName()),
-	))
+		jen.Id("delegate").Id(fileCfg.targetName()).Add(typeParamsRef),
+	}
+	if len(runnerFields) > 0 {
+		proxyFields = append(proxyFields, jen.Id("runners").Struct(runnerFields...))
+	}
+	f.Add(jen.Type().Id(fileCfg.outTypeName).Add(typeParamsDecl).Struct(proxyFields...))
 
 	// Declare the ctor
-	f.Add(jen.Func().Id("New"+fileCfg.outTypeName).Params(
-		jen.Id("delegate").Id(fileCfg.targetName()),
-		jen.Id("runnerFactory").Id("runnerFactory"),
-		jen.Id("options").Op("...").Id("Option"),
-	).Op("*").Id(fileCfg.outTypeName).Block(
+	ctorBody := []jen.Code{
 		// if delegate == nil
 		jen.If(jen.Id("delegate").Op("==").Nil().Block(
 			// panic("...")
@@ -155,11 +223,12 @@ func generateFile(outPkg string, ignoreNoReturnMethods bool, fileCfg *FileConfig
 			jen.Panic(jen.Lit("provided nil runner factory")),
 		)),
 		// c:= &OutTypeName{...}
-		jen.Id("c").Op(":=").Add(jen.Op("&").Id(fileCfg.outTypeName).Values(jen.Dict{
+		jen.Id("c").Op(":=").Add(jen.Op("&").Id(fileCfg.outTypeName).Add(typeParamsRef).Values(jen.Dict{
 			// embed the base struct
 			jen.Id("base"): jen.Op("&").Id("base").Values(jen.Dict{
-				jen.Id("errorPredicate"): jen.Id("RetryAllErrors"),
-				jen.Id("runnerFactory"):  jen.Id("runnerFactory"),
+				jen.Id("errorPredicate"):       jen.Id("RetryAllErrors"),
+				jen.Id("noReturnErrorHandler"): jen.Id("PanicOnNoReturnError"),
+				jen.Id("runnerFactory"):        jen.Id("runnerFactory"),
 			}),
 			jen.Id("delegate"): jen.Id("delegate"),
 		})),
@@ -167,13 +236,67 @@ func generateFile(outPkg string, ignoreNoReturnMethods bool, fileCfg *FileConfig
 		jen.For(jen.Id("_").Op(",").Id("o").Op(":=").Range().Id("options")).Block(
 			jen.Id("o").Call(jen.Id("c").Dot("base")),
 		),
-		jen.Return(jen.Id("c")),
-	))
+	}
+	// The runners are resolved after applying the options as these can qualify their names
+	ctorBody = append(ctorBody, runnerAssign...)
+	ctorBody = append(ctorBody, jen.Return(jen.Id("c")))
+	f.Add(jen.Func().Id("New"+fileCfg.outTypeName).Add(typeParamsDecl).Params(
+		jen.Id("delegate").Id(fileCfg.targetName()).Add(typeParamsRef),
+		jen.Id("runnerFactory").Id("runnerFactory"),
+		jen.Id("options").Op("...").Id("Option"),
+	).Op("*").Id(fileCfg.outTypeName).Add(typeParamsRef).Block(ctorBody...))
+
+	// Declare the result predicate and fallback options for the methods that can be retried
+	var fallbacksFromDelegate []jen.Code
+	for _, mm := range methods {
+		if !mm.ReturnsError {
+			continue
+		}
+		r := retryable.NewRetryable(mm, fileCfg.outTypeName, fileCfg.typeParams, fileCfg.receiverName())
+		s, err := r.ResultPredicateOption()
+		if err != nil {
+			return "", err
+		}
+		if s != nil {
+			f.Add(s)
+		}
+		if s, err = r.FallbackOption(); err != nil {
+			return "", err
+		}
+		f.Add(s)
+
+		// if _, ok := o.fallbacks[methodName]; !ok {
+		//   o.fallbacks[methodName] = func(args..., _ error) (T0, T1, ..., error) {...}
+		// }
+		fallbackRef := jen.Id("o").Dot("fallbacks").Index(mm.ConstantRef(fileCfg.outTypeName))
+		fallbacksFromDelegate = append(fallbacksFromDelegate, jen.If(
+			jen.List(jen.Id("_"), jen.Id("ok")).Op(":=").Add(fallbackRef),
+			jen.Op("!").Id("ok"),
+		).Block(
+			jen.Add(fallbackRef).Op("=").Add(r.FallbackFromDelegate(jen.Id("fallback"))),
+		))
+	}
+
+	// Declare the fallback delegate option that serves the failed calls from another implementation, the fallbacks
+	// configured for a single method take precedence over the ones from the delegate
+	if len(fallbacksFromDelegate) > 0 {
+		f.Add(jen.Func().Id(fmt.Sprintf("With%sFallbackDelegate", fileCfg.outTypeName)).Add(typeParamsDecl).Params(
+			jen.Id("fallback").Id(fileCfg.targetName()).Add(typeParamsRef),
+		).Id("Option").Block(
+			jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("base")).Block(
+				append([]jen.Code{
+					jen.If(jen.Id("o").Dot("fallbacks").Op("==").Nil()).Block(
+						jen.Id("o").Dot("fallbacks").Op("=").Make(jen.Map(jen.Id("string")).Interface()),
+					),
+				}, fallbacksFromDelegate...)...,
+			)),
+		))
+	}
 
 	// Declare all of our proxy methods
 	for _, mm := range methods {
 		if mm.ReturnsError {
-			r := retryable.NewRetryable(mm, fileCfg.outTypeName, fileCfg.receiverName())
+			r := retryable.NewRetryable(mm, fileCfg.outTypeName, fileCfg.typeParams, fileCfg.receiverName())
 			s, err := r.Statement()
 			if err != nil {
 				return "", err
@@ -182,9 +305,9 @@ func generateFile(outPkg string, ignoreNoReturnMethods bool, fileCfg *FileConfig
 		} else {
 			var p statement
 			if ignoreNoReturnMethods {
-				p = passthrough.NewPassThrough(mm, fileCfg.outTypeName, fileCfg.receiverName())
+				p = passthrough.NewPassThrough(mm, fileCfg.outTypeName, fileCfg.typeParams, fileCfg.receiverName())
 			} else {
-				p = noret.NewNoReturn(mm, fileCfg.outTypeName, fileCfg.receiverName())
+				p = noret.NewNoReturn(mm, fileCfg.outTypeName, fileCfg.typeParams, fileCfg.receiverName())
 			}
 			s, err := p.Statement()
 			if err != nil {
@@ -196,19 +319,24 @@ func generateFile(outPkg string, ignoreNoReturnMethods bool, fileCfg *FileConfig
 	return renderToString(f)
 }
 
-func generateCommon(outPkg string) (string, error) {
+func generateCommon(outPkg, runnerPkg string) (string, error) {
 	f := jen.NewFile(outPkg)
 	f.HeaderComment(fileHeader)
 
 	// Declare base impl that will be used to hold the common fields
 	f.Add(jen.Type().Id("base").Struct(
 		jen.Id("errorPredicate").Add(jen.Func().Params(jen.Id("string"), jen.Id("error")).Params(jen.Bool())),
+		jen.Id("methodErrorPredicates").Map(jen.Id("string")).Func().Params(jen.Id("error")).Params(jen.Bool()),
+		jen.Id("resultPredicates").Map(jen.Id("string")).Interface(),
+		jen.Id("fallbacks").Map(jen.Id("string")).Interface(),
+		jen.Id("noReturnErrorHandler").Func().Params(jen.Id("string"), jen.Id("error")),
 		jen.Id("runnerFactory").Id("runnerFactory"),
+		jen.Id("instanceName").Id("string"),
 	))
 
 	// Declares the runner's factory
 	f.Add(jen.Type().Id("runnerFactory").Interface(
-		jen.Id("GetRunner").Params(jen.Id("name").Id("string")).Qual("github.com/slok/goresilience", "Runner"),
+		jen.Id("GetRunner").Params(jen.Id("name").Id("string")).Qual(runnerPkg, "Runner"),
 	))
 
 	// Declare the RetryAllErrors predicate that enables the middleware on all errors received from proxy call
@@ -216,6 +344,16 @@ func generateCommon(outPkg string) (string, error) {
 		jen.Return(jen.Lit(true)),
 	))
 
+	// Declare the PanicOnNoReturnError handler that panics with the errors received from proxy calls to methods that
+	// don't return anything
+	f.Add(jen.Var().Id("PanicOnNoReturnError").Op("=").Func().Params(jen.Id("_").Id("string"), jen.Id("err").Id("error")).Block(
+		jen.Panic(jen.Id("err")),
+	))
+
+	// Declare the ErrRetryableResult error that is used to retry the results rejected by a result predicate
+	f.Add(jen.Comment("ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate"))
+	f.Add(jen.Var().Id("ErrRetryableResult").Op("=").Qual("errors", "New").Call(jen.Lit("retryable result")))
+
 	// Declare the Option type that allows to configure the service
 	f.Add(jen.Type().Id("Option").Func().Params(jen.Op("*").Id("base")))
 
@@ -226,13 +364,53 @@ func generateCommon(outPkg string) (string, error) {
 		)),
 	))
 
-	// Declare our runner helper
-	f.Add(jen.Func().Params(jen.Id("b").Op("*").Id("base")).Id("run").Params(
-		jen.Id("ctx").Qual("context", "Context"),
+	// Declare the WithInstanceName Option which qualifies the names of the runners used by the proxy with the name of
+	// the instance, this allows for multiple instances of a proxy to not share their runners
+	f.Add(jen.Func().Id("WithInstanceName").Params(jen.Id("name").Id("string")).Params(jen.Id("Option")).Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("base")).Block(
+			jen.Id("o").Dot("instanceName").Op("=").Id("name"),
+		)),
+	))
+
+	// Declare the WithNoReturnErrorHandler Option which configures the handler of the errors from methods that don't
+	// return anything
+	f.Add(jen.Func().Id("WithNoReturnErrorHandler").Params(jen.Id("fn").Id("func").Params(jen.Id("method").Id("string"), jen.Id("err").Id("error"))).Params(jen.Id("Option")).Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("base")).Block(
+			jen.Id("o").Dot("noReturnErrorHandler").Op("=").Id("fn"),
+		)),
+	))
+
+	// Declare the WithMethodErrorPredicate Option which configures the predicate to determine which errors should be
+	// retried for a single method, it takes precedence over the predicate given in WithRetryableErrorPredicate
+	f.Add(jen.Func().Id("WithMethodErrorPredicate").Params(jen.Id("method").Id("string"), jen.Id("fn").Id("func").Params(jen.Id("error")).Params(jen.Bool())).Params(jen.Id("Option")).Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("base")).Block(
+			jen.If(jen.Id("o").Dot("methodErrorPredicates").Op("==").Nil()).Block(
+				jen.Id("o").Dot("methodErrorPredicates").Op("=").Make(jen.Map(jen.Id("string")).Func().Params(jen.Id("error")).Params(jen.Bool())),
+			),
+			jen.Id("o").Dot("methodErrorPredicates").Index(jen.Id("method")).Op("=").Id("fn"),
+		)),
+	))
+
+	// Declare our retry decision helper, the method's predicate is used when present otherwise it falls back to the
+	// predicate shared by all methods
+	f.Add(jen.Func().Params(jen.Id("b").Op("*").Id("base")).Id("shouldRetry").Params(
+		jen.Id("method").Id("string"),
+		jen.Id("err").Id("error"),
+	).Bool().Block(
+		jen.If(jen.List(jen.Id("fn"), jen.Id("ok")).Op(":=").Id("b").Dot("methodErrorPredicates").Index(jen.Id("method")), jen.Id("ok")).Block(
+			jen.Return(jen.Id("fn").Call(jen.Id("err"))),
+		),
+		jen.Return(jen.Id("b").Dot("errorPredicate").Call(jen.Id("method"), jen.Id("err"))),
+	))
+
+	// Declare our runner helper, the runners are resolved once when the proxy is created
+	f.Add(jen.Func().Params(jen.Id("b").Op("*").Id("base")).Id("runner").Params(
 		jen.Id("name").Id("string"),
-		jen.Id("fn").Func().Params(jen.Id("ctx").Qual("context", "Context")).Id("error"),
-	).Id("error").Block(
-		jen.Return(jen.Id("b").Dot("runnerFactory").Dot("GetRunner").Call(jen.Id("name")).Dot("Run").Call(jen.Id("ctx"), jen.Id("fn"))),
+	).Qual(runnerPkg, "Runner").Block(
+		jen.If(jen.Id("b").Dot("instanceName").Op("!=").Lit("")).Block(
+			jen.Id("name").Op("=").Id("b").Dot("instanceName").Op("+").Lit(".").Op("+").Id("name"),
+		),
+		jen.Return(jen.Id("b").Dot("runnerFactory").Dot("GetRunner").Call(jen.Id("name"))),
 	))
 	return renderToString(f)
 }
@@ -241,24 +419,42 @@ func generateConstants(outPkg string, meta []*fileMeta) (string, error) {
 	f := jen.NewFile(outPkg)
 	f.HeaderComment(fileHeader)
 
+	// The constants are sorted by type so that the same file is generated regardless of the order of the targets
+	meta = append([]*fileMeta(nil), meta...)
+	sort.SliceStable(meta, func(i, j int) bool {
+		return meta[i].fileConfig.outTypeName < meta[j].fileConfig.outTypeName
+	})
 	for _, fm := range meta {
 		var fields []jen.Code
 		var constantAssign []jen.Code
+		var all []jen.Code
 		for _, m := range fm.methods {
 			fields = append(fields, jen.Id(m.Name).Id("string"))
-			constantAssign = append(constantAssign, jen.Id(m.Name).Op(":").Lit(m.Name).Op(","))
+			// The method names are qualified by the type so that proxies with methods of the same name don't share runners
+			constantAssign = append(constantAssign, jen.Id(m.Name).Op(":").Lit(fm.fileConfig.outTypeName+"."+m.Name).Op(","))
+			all = append(all, jen.Id("m").Dot(m.Name))
 		}
 
 		constObjName := fmt.Sprintf("%sMethods", fm.fileConfig.outTypeName)
+		// The constants need a named type to expose the list of all the methods
+		constTypeName := strings.ToLower(constObjName[0:1]) + constObjName[1:]
 		log.Debug().Msgf("Adding constants for type %s", fm.fileConfig.outTypeName)
-		f.Add(jen.Comment(fmt.Sprintf("%s are the methods in %s", constObjName, fm.fileConfig.outTypeName)))
+		f.Add(jen.Comment(fmt.Sprintf("%s are the methods in %s, these are the names of the runners used by each method", constObjName, fm.fileConfig.outTypeName)))
 		f.Add(
-			jen.Var().Id(constObjName).Op("=").Struct(
-				fields...,
-			).Block(
+			jen.Var().Id(constObjName).Op("=").Id(constTypeName).Block(
 				constantAssign...,
 			),
 		)
+		f.Line()
+		f.Add(jen.Comment(fmt.Sprintf("%s holds the names of the methods in %s", constTypeName, fm.fileConfig.outTypeName)))
+		f.Add(jen.Type().Id(constTypeName).Struct(fields...))
+		f.Line()
+		f.Add(jen.Comment(fmt.Sprintf("All returns the names of all the methods in %s", fm.fileConfig.outTypeName)))
+		f.Add(
+			jen.Func().Params(jen.Id("m").Id(constTypeName)).Id("All").Params().Index().String().Block(
+				jen.Return(jen.Index().String().Values(all...)),
+			),
+		)
 	}
 
 	return renderToString(f)
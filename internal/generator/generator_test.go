@@ -7,6 +7,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/packages/packagestest"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -47,13 +50,18 @@ type Service interface {
 package resilient
 
 import (
-	"context"
+	"errors"
 	goresilience "github.com/slok/goresilience"
 )
 
 type base struct {
-	errorPredicate func(string, error) bool
-	runnerFactory  runnerFactory
+	errorPredicate        func(string, error) bool
+	methodErrorPredicates map[string]func(error) bool
+	resultPredicates      map[string]interface{}
+	fallbacks             map[string]interface{}
+	noReturnErrorHandler  func(string, error)
+	runnerFactory         runnerFactory
+	instanceName          string
 }
 type runnerFactory interface {
 	GetRunner(name string) goresilience.Runner
@@ -62,6 +70,12 @@ type runnerFactory interface {
 var RetryAllErrors = func(_ string, _ error) bool {
 	return true
 }
+var PanicOnNoReturnError = func(_ string, err error) {
+	panic(err)
+}
+
+// ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate
+var ErrRetryableResult = errors.New("retryable result")
 
 type Option func(*base)
 
@@ -70,21 +84,56 @@ func WithRetryableErrorPredicate(fn func(string, error) bool) Option {
 		o.errorPredicate = fn
 	}
 }
-func (b *base) run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
-	return b.runnerFactory.GetRunner(name).Run(ctx, fn)
+func WithInstanceName(name string) Option {
+	return func(o *base) {
+		o.instanceName = name
+	}
+}
+func WithNoReturnErrorHandler(fn func(method string, err error)) Option {
+	return func(o *base) {
+		o.noReturnErrorHandler = fn
+	}
+}
+func WithMethodErrorPredicate(method string, fn func(error) bool) Option {
+	return func(o *base) {
+		if o.methodErrorPredicates == nil {
+			o.methodErrorPredicates = make(map[string]func(error) bool)
+		}
+		o.methodErrorPredicates[method] = fn
+	}
+}
+func (b *base) shouldRetry(method string, err error) bool {
+	if fn, ok := b.methodErrorPredicates[method]; ok {
+		return fn(err)
+	}
+	return b.errorPredicate(method, err)
+}
+func (b *base) runner(name string) goresilience.Runner {
+	if b.instanceName != "" {
+		name = b.instanceName + "." + name
+	}
+	return b.runnerFactory.GetRunner(name)
 }
 `,
 				Constants: `// Code generated by reinforcer, DO NOT EDIT.
 
 package resilient
 
-// GeneratedServiceMethods are the methods in GeneratedService
-var GeneratedServiceMethods = struct {
+// GeneratedServiceMethods are the methods in GeneratedService, these are the names of the runners used by each method
+var GeneratedServiceMethods = generatedServiceMethods{
+	A: "GeneratedService.A",
+	B: "GeneratedService.B",
+}
+
+// generatedServiceMethods holds the names of the methods in GeneratedService
+type generatedServiceMethods struct {
 	A string
 	B string
-}{
-	A: "A",
-	B: "B",
+}
+
+// All returns the names of all the methods in GeneratedService
+func (m generatedServiceMethods) All() []string {
+	return []string{m.A, m.B}
 }
 `,
 				Files: []*generator.GeneratedFile{
@@ -94,15 +143,23 @@ var GeneratedServiceMethods = struct {
 
 package resilient
 
-import "context"
+import (
+	"context"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
+)
 
 type targetService interface {
 	A(ctx context.Context) error
-	B(ctx context.Context, arg1 func(string) bool) (func() bool, error)
+	B(ctx context.Context, fn func(string) bool) (func() bool, error)
 }
 type GeneratedService struct {
 	*base
 	delegate targetService
+	runners  struct {
+		A goresilience.Runner
+		B goresilience.Runner
+	}
 }
 
 func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, options ...Option) *GeneratedService {
@@ -114,48 +171,141 @@ func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, op
 	}
 	c := &GeneratedService{
 		base: &base{
-			errorPredicate: RetryAllErrors,
-			runnerFactory:  runnerFactory,
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
 		},
 		delegate: delegate,
 	}
 	for _, o := range options {
 		o(c.base)
 	}
+	c.runners.A = c.runner(GeneratedServiceMethods.A)
+	c.runners.B = c.runner(GeneratedServiceMethods.B)
 	return c
 }
+func WithGeneratedServiceAFallback(fn func(ctx context.Context, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.A] = fn
+	}
+}
+func WithGeneratedServiceBResultPredicate(fn func(func() bool, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[GeneratedServiceMethods.B] = fn
+	}
+}
+func WithGeneratedServiceBFallback(fn func(ctx context.Context, fn func(string) bool, err error) (func() bool, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.B] = fn
+	}
+}
+func WithGeneratedServiceFallbackDelegate(fallback targetService) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.A]; !ok {
+			o.fallbacks[GeneratedServiceMethods.A] = func(ctx context.Context, _ error) error {
+				return fallback.A(ctx)
+			}
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.B]; !ok {
+			o.fallbacks[GeneratedServiceMethods.B] = func(ctx context.Context, fn func(string) bool, _ error) (func() bool, error) {
+				return fallback.B(ctx, fn)
+			}
+		}
+	}
+}
 func (g *GeneratedService) A(ctx context.Context) error {
 	var nonRetryableErr error
-	err := g.run(ctx, GeneratedServiceMethods.A, func(ctx context.Context) error {
-		var err error
-		err = g.delegate.A(ctx)
-		if g.errorPredicate(GeneratedServiceMethods.A, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.A.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := g.delegate.A(ctx)
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.A, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.A].(func(ctx context.Context, err error) error); fallback != nil {
+			return fallback(ctx, err)
+		}
+		return err
 	}
-	return err
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
+	}
+	return nonRetryableErr
 }
-func (g *GeneratedService) B(ctx context.Context, arg1 func(string) bool) (func() bool, error) {
+func (g *GeneratedService) B(ctx context.Context, fn func(string) bool) (func() bool, error) {
 	var nonRetryableErr error
 	var r0 func() bool
-	err := g.run(ctx, GeneratedServiceMethods.B, func(ctx context.Context) error {
-		var err error
-		r0, err = g.delegate.B(ctx, arg1)
-		if g.errorPredicate(GeneratedServiceMethods.B, err) {
+	type results struct {
+		attempt int64
+		r0      func() bool
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.B.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := g.delegate.B(ctx, fn)
+		if p, ok := g.resultPredicates[GeneratedServiceMethods.B].(func(func() bool, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.B, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return r0, nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.B].(func(ctx context.Context, fn func(string) bool, err error) (func() bool, error)); fallback != nil {
+			return fallback(ctx, fn, err)
+		}
+		return *new(func() bool), err
 	}
-	return r0, err
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
+	}
+	return r0, nonRetryableErr
 }
 `,
 					},
@@ -192,13 +342,18 @@ type Service interface {
 package resilient
 
 import (
-	"context"
+	"errors"
 	goresilience "github.com/slok/goresilience"
 )
 
 type base struct {
-	errorPredicate func(string, error) bool
-	runnerFactory  runnerFactory
+	errorPredicate        func(string, error) bool
+	methodErrorPredicates map[string]func(error) bool
+	resultPredicates      map[string]interface{}
+	fallbacks             map[string]interface{}
+	noReturnErrorHandler  func(string, error)
+	runnerFactory         runnerFactory
+	instanceName          string
 }
 type runnerFactory interface {
 	GetRunner(name string) goresilience.Runner
@@ -207,6 +362,12 @@ type runnerFactory interface {
 var RetryAllErrors = func(_ string, _ error) bool {
 	return true
 }
+var PanicOnNoReturnError = func(_ string, err error) {
+	panic(err)
+}
+
+// ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate
+var ErrRetryableResult = errors.New("retryable result")
 
 type Option func(*base)
 
@@ -215,29 +376,64 @@ func WithRetryableErrorPredicate(fn func(string, error) bool) Option {
 		o.errorPredicate = fn
 	}
 }
-func (b *base) run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
-	return b.runnerFactory.GetRunner(name).Run(ctx, fn)
+func WithInstanceName(name string) Option {
+	return func(o *base) {
+		o.instanceName = name
+	}
+}
+func WithNoReturnErrorHandler(fn func(method string, err error)) Option {
+	return func(o *base) {
+		o.noReturnErrorHandler = fn
+	}
+}
+func WithMethodErrorPredicate(method string, fn func(error) bool) Option {
+	return func(o *base) {
+		if o.methodErrorPredicates == nil {
+			o.methodErrorPredicates = make(map[string]func(error) bool)
+		}
+		o.methodErrorPredicates[method] = fn
+	}
+}
+func (b *base) shouldRetry(method string, err error) bool {
+	if fn, ok := b.methodErrorPredicates[method]; ok {
+		return fn(err)
+	}
+	return b.errorPredicate(method, err)
+}
+func (b *base) runner(name string) goresilience.Runner {
+	if b.instanceName != "" {
+		name = b.instanceName + "." + name
+	}
+	return b.runnerFactory.GetRunner(name)
 }
 `,
 				Constants: `// Code generated by reinforcer, DO NOT EDIT.
 
 package resilient
 
-// GeneratedServiceMethods are the methods in GeneratedService
-var GeneratedServiceMethods = struct {
+// GeneratedServiceMethods are the methods in GeneratedService, these are the names of the runners used by each method
+var GeneratedServiceMethods = generatedServiceMethods{
+	A:           "GeneratedService.A",
+	B:           "GeneratedService.B",
+	C:           "GeneratedService.C",
+	GetUserID:   "GeneratedService.GetUserID",
+	GetUserID2:  "GeneratedService.GetUserID2",
+	HasVariadic: "GeneratedService.HasVariadic",
+}
+
+// generatedServiceMethods holds the names of the methods in GeneratedService
+type generatedServiceMethods struct {
 	A           string
 	B           string
 	C           string
 	GetUserID   string
 	GetUserID2  string
 	HasVariadic string
-}{
-	A:           "A",
-	B:           "B",
-	C:           "C",
-	GetUserID:   "GetUserID",
-	GetUserID2:  "GetUserID2",
-	HasVariadic: "HasVariadic",
+}
+
+// All returns the names of all the methods in GeneratedService
+func (m generatedServiceMethods) All() []string {
+	return []string{m.A, m.B, m.C, m.GetUserID, m.GetUserID2, m.HasVariadic}
 }
 `,
 				Files: []*generator.GeneratedFile{
@@ -250,19 +446,29 @@ package resilient
 import (
 	"context"
 	unresilient "github.com/csueiras/fake/unresilient"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
 )
 
 type targetService interface {
 	A()
 	B(ctx context.Context)
-	C(ctx context.Context, arg1 int, arg2 *int32, arg3 *unresilient.User)
-	GetUserID(ctx context.Context, arg1 string) (string, error)
-	GetUserID2(ctx context.Context, arg1 *string) (*unresilient.User, error)
-	HasVariadic(ctx context.Context, arg1 ...string) error
+	C(ctx context.Context, param1 int, param2 *int32, param3 *unresilient.User)
+	GetUserID(ctx context.Context, userID string) (string, error)
+	GetUserID2(ctx context.Context, userID *string) (*unresilient.User, error)
+	HasVariadic(ctx context.Context, fields ...string) error
 }
 type GeneratedService struct {
 	*base
 	delegate targetService
+	runners  struct {
+		A           goresilience.Runner
+		B           goresilience.Runner
+		C           goresilience.Runner
+		GetUserID   goresilience.Runner
+		GetUserID2  goresilience.Runner
+		HasVariadic goresilience.Runner
+	}
 }
 
 func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, options ...Option) *GeneratedService {
@@ -274,92 +480,238 @@ func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, op
 	}
 	c := &GeneratedService{
 		base: &base{
-			errorPredicate: RetryAllErrors,
-			runnerFactory:  runnerFactory,
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
 		},
 		delegate: delegate,
 	}
 	for _, o := range options {
 		o(c.base)
 	}
+	c.runners.A = c.runner(GeneratedServiceMethods.A)
+	c.runners.B = c.runner(GeneratedServiceMethods.B)
+	c.runners.C = c.runner(GeneratedServiceMethods.C)
+	c.runners.GetUserID = c.runner(GeneratedServiceMethods.GetUserID)
+	c.runners.GetUserID2 = c.runner(GeneratedServiceMethods.GetUserID2)
+	c.runners.HasVariadic = c.runner(GeneratedServiceMethods.HasVariadic)
 	return c
 }
+func WithGeneratedServiceGetUserIDResultPredicate(fn func(string, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[GeneratedServiceMethods.GetUserID] = fn
+	}
+}
+func WithGeneratedServiceGetUserIDFallback(fn func(ctx context.Context, userID string, err error) (string, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.GetUserID] = fn
+	}
+}
+func WithGeneratedServiceGetUserID2ResultPredicate(fn func(*unresilient.User, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[GeneratedServiceMethods.GetUserID2] = fn
+	}
+}
+func WithGeneratedServiceGetUserID2Fallback(fn func(ctx context.Context, userID *string, err error) (*unresilient.User, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.GetUserID2] = fn
+	}
+}
+func WithGeneratedServiceHasVariadicFallback(fn func(ctx context.Context, fields []string, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.HasVariadic] = fn
+	}
+}
+func WithGeneratedServiceFallbackDelegate(fallback targetService) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.GetUserID]; !ok {
+			o.fallbacks[GeneratedServiceMethods.GetUserID] = func(ctx context.Context, userID string, _ error) (string, error) {
+				return fallback.GetUserID(ctx, userID)
+			}
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.GetUserID2]; !ok {
+			o.fallbacks[GeneratedServiceMethods.GetUserID2] = func(ctx context.Context, userID *string, _ error) (*unresilient.User, error) {
+				return fallback.GetUserID2(ctx, userID)
+			}
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.HasVariadic]; !ok {
+			o.fallbacks[GeneratedServiceMethods.HasVariadic] = func(ctx context.Context, fields []string, _ error) error {
+				return fallback.HasVariadic(ctx, fields...)
+			}
+		}
+	}
+}
 func (g *GeneratedService) A() {
-	err := g.run(context.Background(), GeneratedServiceMethods.A, func(_ context.Context) error {
+	err := g.runners.A.Run(context.Background(), func(_ context.Context) error {
 		g.delegate.A()
 		return nil
 	})
 	if err != nil {
-		panic(err)
+		g.noReturnErrorHandler(GeneratedServiceMethods.A, err)
 	}
 }
 func (g *GeneratedService) B(ctx context.Context) {
-	err := g.run(ctx, GeneratedServiceMethods.B, func(ctx context.Context) error {
+	err := g.runners.B.Run(ctx, func(ctx context.Context) error {
 		g.delegate.B(ctx)
 		return nil
 	})
 	if err != nil {
-		panic(err)
+		g.noReturnErrorHandler(GeneratedServiceMethods.B, err)
 	}
 }
-func (g *GeneratedService) C(ctx context.Context, arg1 int, arg2 *int32, arg3 *unresilient.User) {
-	err := g.run(ctx, GeneratedServiceMethods.C, func(ctx context.Context) error {
-		g.delegate.C(ctx, arg1, arg2, arg3)
+func (g *GeneratedService) C(ctx context.Context, param1 int, param2 *int32, param3 *unresilient.User) {
+	err := g.runners.C.Run(ctx, func(ctx context.Context) error {
+		g.delegate.C(ctx, param1, param2, param3)
 		return nil
 	})
 	if err != nil {
-		panic(err)
+		g.noReturnErrorHandler(GeneratedServiceMethods.C, err)
 	}
 }
-func (g *GeneratedService) GetUserID(ctx context.Context, arg1 string) (string, error) {
+func (g *GeneratedService) GetUserID(ctx context.Context, userID string) (string, error) {
 	var nonRetryableErr error
 	var r0 string
-	err := g.run(ctx, GeneratedServiceMethods.GetUserID, func(ctx context.Context) error {
-		var err error
-		r0, err = g.delegate.GetUserID(ctx, arg1)
-		if g.errorPredicate(GeneratedServiceMethods.GetUserID, err) {
+	type results struct {
+		attempt int64
+		r0      string
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.GetUserID.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := g.delegate.GetUserID(ctx, userID)
+		if p, ok := g.resultPredicates[GeneratedServiceMethods.GetUserID].(func(string, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.GetUserID, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return r0, nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.GetUserID].(func(ctx context.Context, userID string, err error) (string, error)); fallback != nil {
+			return fallback(ctx, userID, err)
+		}
+		return *new(string), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
 	}
-	return r0, err
+	return r0, nonRetryableErr
 }
-func (g *GeneratedService) GetUserID2(ctx context.Context, arg1 *string) (*unresilient.User, error) {
+func (g *GeneratedService) GetUserID2(ctx context.Context, userID *string) (*unresilient.User, error) {
 	var nonRetryableErr error
 	var r0 *unresilient.User
-	err := g.run(ctx, GeneratedServiceMethods.GetUserID2, func(ctx context.Context) error {
-		var err error
-		r0, err = g.delegate.GetUserID2(ctx, arg1)
-		if g.errorPredicate(GeneratedServiceMethods.GetUserID2, err) {
+	type results struct {
+		attempt int64
+		r0      *unresilient.User
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.GetUserID2.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := g.delegate.GetUserID2(ctx, userID)
+		if p, ok := g.resultPredicates[GeneratedServiceMethods.GetUserID2].(func(*unresilient.User, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.GetUserID2, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return r0, nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.GetUserID2].(func(ctx context.Context, userID *string, err error) (*unresilient.User, error)); fallback != nil {
+			return fallback(ctx, userID, err)
+		}
+		return *new(*unresilient.User), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
 	}
-	return r0, err
+	return r0, nonRetryableErr
 }
-func (g *GeneratedService) HasVariadic(ctx context.Context, arg1 ...string) error {
+func (g *GeneratedService) HasVariadic(ctx context.Context, fields ...string) error {
 	var nonRetryableErr error
-	err := g.run(ctx, GeneratedServiceMethods.HasVariadic, func(ctx context.Context) error {
-		var err error
-		err = g.delegate.HasVariadic(ctx, arg1...)
-		if g.errorPredicate(GeneratedServiceMethods.HasVariadic, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.HasVariadic.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := g.delegate.HasVariadic(ctx, fields...)
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.HasVariadic, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.HasVariadic].(func(ctx context.Context, fields []string, err error) error); fallback != nil {
+			return fallback(ctx, fields, err)
+		}
+		return err
 	}
-	return err
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
+	}
+	return nonRetryableErr
 }
 `,
 					},
@@ -392,13 +744,18 @@ type Service interface {
 package resilient
 
 import (
-	"context"
+	"errors"
 	goresilience "github.com/slok/goresilience"
 )
 
 type base struct {
-	errorPredicate func(string, error) bool
-	runnerFactory  runnerFactory
+	errorPredicate        func(string, error) bool
+	methodErrorPredicates map[string]func(error) bool
+	resultPredicates      map[string]interface{}
+	fallbacks             map[string]interface{}
+	noReturnErrorHandler  func(string, error)
+	runnerFactory         runnerFactory
+	instanceName          string
 }
 type runnerFactory interface {
 	GetRunner(name string) goresilience.Runner
@@ -407,6 +764,12 @@ type runnerFactory interface {
 var RetryAllErrors = func(_ string, _ error) bool {
 	return true
 }
+var PanicOnNoReturnError = func(_ string, err error) {
+	panic(err)
+}
+
+// ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate
+var ErrRetryableResult = errors.New("retryable result")
 
 type Option func(*base)
 
@@ -415,21 +778,56 @@ func WithRetryableErrorPredicate(fn func(string, error) bool) Option {
 		o.errorPredicate = fn
 	}
 }
-func (b *base) run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
-	return b.runnerFactory.GetRunner(name).Run(ctx, fn)
+func WithInstanceName(name string) Option {
+	return func(o *base) {
+		o.instanceName = name
+	}
+}
+func WithNoReturnErrorHandler(fn func(method string, err error)) Option {
+	return func(o *base) {
+		o.noReturnErrorHandler = fn
+	}
+}
+func WithMethodErrorPredicate(method string, fn func(error) bool) Option {
+	return func(o *base) {
+		if o.methodErrorPredicates == nil {
+			o.methodErrorPredicates = make(map[string]func(error) bool)
+		}
+		o.methodErrorPredicates[method] = fn
+	}
+}
+func (b *base) shouldRetry(method string, err error) bool {
+	if fn, ok := b.methodErrorPredicates[method]; ok {
+		return fn(err)
+	}
+	return b.errorPredicate(method, err)
+}
+func (b *base) runner(name string) goresilience.Runner {
+	if b.instanceName != "" {
+		name = b.instanceName + "." + name
+	}
+	return b.runnerFactory.GetRunner(name)
 }
 `,
 				Constants: `// Code generated by reinforcer, DO NOT EDIT.
 
 package resilient
 
-// GeneratedServiceMethods are the methods in GeneratedService
-var GeneratedServiceMethods = struct {
+// GeneratedServiceMethods are the methods in GeneratedService, these are the names of the runners used by each method
+var GeneratedServiceMethods = generatedServiceMethods{
+	A: "GeneratedService.A",
+	B: "GeneratedService.B",
+}
+
+// generatedServiceMethods holds the names of the methods in GeneratedService
+type generatedServiceMethods struct {
 	A string
 	B string
-}{
-	A: "A",
-	B: "B",
+}
+
+// All returns the names of all the methods in GeneratedService
+func (m generatedServiceMethods) All() []string {
+	return []string{m.A, m.B}
 }
 `,
 				Files: []*generator.GeneratedFile{
@@ -439,15 +837,22 @@ var GeneratedServiceMethods = struct {
 
 package resilient
 
-import "context"
+import (
+	"context"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
+)
 
 type targetService interface {
 	A()
-	B(ctx context.Context, arg1 string) (string, error)
+	B(ctx context.Context, userID string) (string, error)
 }
 type GeneratedService struct {
 	*base
 	delegate targetService
+	runners  struct {
+		B goresilience.Runner
+	}
 }
 
 func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, options ...Option) *GeneratedService {
@@ -459,35 +864,93 @@ func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, op
 	}
 	c := &GeneratedService{
 		base: &base{
-			errorPredicate: RetryAllErrors,
-			runnerFactory:  runnerFactory,
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
 		},
 		delegate: delegate,
 	}
 	for _, o := range options {
 		o(c.base)
 	}
+	c.runners.B = c.runner(GeneratedServiceMethods.B)
 	return c
 }
+func WithGeneratedServiceBResultPredicate(fn func(string, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[GeneratedServiceMethods.B] = fn
+	}
+}
+func WithGeneratedServiceBFallback(fn func(ctx context.Context, userID string, err error) (string, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.B] = fn
+	}
+}
+func WithGeneratedServiceFallbackDelegate(fallback targetService) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.B]; !ok {
+			o.fallbacks[GeneratedServiceMethods.B] = func(ctx context.Context, userID string, _ error) (string, error) {
+				return fallback.B(ctx, userID)
+			}
+		}
+	}
+}
 func (g *GeneratedService) A() {
 	g.delegate.A()
 }
-func (g *GeneratedService) B(ctx context.Context, arg1 string) (string, error) {
+func (g *GeneratedService) B(ctx context.Context, userID string) (string, error) {
 	var nonRetryableErr error
 	var r0 string
-	err := g.run(ctx, GeneratedServiceMethods.B, func(ctx context.Context) error {
-		var err error
-		r0, err = g.delegate.B(ctx, arg1)
-		if g.errorPredicate(GeneratedServiceMethods.B, err) {
+	type results struct {
+		attempt int64
+		r0      string
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.B.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := g.delegate.B(ctx, userID)
+		if p, ok := g.resultPredicates[GeneratedServiceMethods.B].(func(string, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.B, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return r0, nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.B].(func(ctx context.Context, userID string, err error) (string, error)); fallback != nil {
+			return fallback(ctx, userID, err)
+		}
+		return *new(string), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
 	}
-	return r0, err
+	return r0, nonRetryableErr
 }
 `,
 					},
@@ -517,13 +980,18 @@ type Service interface {
 package resilient
 
 import (
-	"context"
+	"errors"
 	goresilience "github.com/slok/goresilience"
 )
 
 type base struct {
-	errorPredicate func(string, error) bool
-	runnerFactory  runnerFactory
+	errorPredicate        func(string, error) bool
+	methodErrorPredicates map[string]func(error) bool
+	resultPredicates      map[string]interface{}
+	fallbacks             map[string]interface{}
+	noReturnErrorHandler  func(string, error)
+	runnerFactory         runnerFactory
+	instanceName          string
 }
 type runnerFactory interface {
 	GetRunner(name string) goresilience.Runner
@@ -532,6 +1000,12 @@ type runnerFactory interface {
 var RetryAllErrors = func(_ string, _ error) bool {
 	return true
 }
+var PanicOnNoReturnError = func(_ string, err error) {
+	panic(err)
+}
+
+// ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate
+var ErrRetryableResult = errors.New("retryable result")
 
 type Option func(*base)
 
@@ -540,19 +1014,54 @@ func WithRetryableErrorPredicate(fn func(string, error) bool) Option {
 		o.errorPredicate = fn
 	}
 }
-func (b *base) run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
-	return b.runnerFactory.GetRunner(name).Run(ctx, fn)
+func WithInstanceName(name string) Option {
+	return func(o *base) {
+		o.instanceName = name
+	}
+}
+func WithNoReturnErrorHandler(fn func(method string, err error)) Option {
+	return func(o *base) {
+		o.noReturnErrorHandler = fn
+	}
+}
+func WithMethodErrorPredicate(method string, fn func(error) bool) Option {
+	return func(o *base) {
+		if o.methodErrorPredicates == nil {
+			o.methodErrorPredicates = make(map[string]func(error) bool)
+		}
+		o.methodErrorPredicates[method] = fn
+	}
+}
+func (b *base) shouldRetry(method string, err error) bool {
+	if fn, ok := b.methodErrorPredicates[method]; ok {
+		return fn(err)
+	}
+	return b.errorPredicate(method, err)
+}
+func (b *base) runner(name string) goresilience.Runner {
+	if b.instanceName != "" {
+		name = b.instanceName + "." + name
+	}
+	return b.runnerFactory.GetRunner(name)
 }
 `,
 				Constants: `// Code generated by reinforcer, DO NOT EDIT.
 
 package resilient
 
-// GeneratedServiceMethods are the methods in GeneratedService
-var GeneratedServiceMethods = struct {
+// GeneratedServiceMethods are the methods in GeneratedService, these are the names of the runners used by each method
+var GeneratedServiceMethods = generatedServiceMethods{
+	SaveUser: "GeneratedService.SaveUser",
+}
+
+// generatedServiceMethods holds the names of the methods in GeneratedService
+type generatedServiceMethods struct {
 	SaveUser string
-}{
-	SaveUser: "SaveUser",
+}
+
+// All returns the names of all the methods in GeneratedService
+func (m generatedServiceMethods) All() []string {
+	return []string{m.SaveUser}
 }
 `,
 				Files: []*generator.GeneratedFile{
@@ -565,14 +1074,19 @@ package resilient
 import (
 	"context"
 	unresilient "github.com/csueiras/fake/unresilient"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
 )
 
 type targetService interface {
-	SaveUser(arg0 *unresilient.T) error
+	SaveUser(user *unresilient.T) error
 }
 type GeneratedService struct {
 	*base
 	delegate targetService
+	runners  struct {
+		SaveUser goresilience.Runner
+	}
 }
 
 func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, options ...Option) *GeneratedService {
@@ -584,31 +1098,74 @@ func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, op
 	}
 	c := &GeneratedService{
 		base: &base{
-			errorPredicate: RetryAllErrors,
-			runnerFactory:  runnerFactory,
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
 		},
 		delegate: delegate,
 	}
 	for _, o := range options {
 		o(c.base)
 	}
+	c.runners.SaveUser = c.runner(GeneratedServiceMethods.SaveUser)
 	return c
 }
-func (g *GeneratedService) SaveUser(arg0 *unresilient.T) error {
+func WithGeneratedServiceSaveUserFallback(fn func(user *unresilient.T, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.SaveUser] = fn
+	}
+}
+func WithGeneratedServiceFallbackDelegate(fallback targetService) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.SaveUser]; !ok {
+			o.fallbacks[GeneratedServiceMethods.SaveUser] = func(user *unresilient.T, _ error) error {
+				return fallback.SaveUser(user)
+			}
+		}
+	}
+}
+func (g *GeneratedService) SaveUser(user *unresilient.T) error {
 	var nonRetryableErr error
-	err := g.run(context.Background(), GeneratedServiceMethods.SaveUser, func(_ context.Context) error {
-		var err error
-		err = g.delegate.SaveUser(arg0)
-		if g.errorPredicate(GeneratedServiceMethods.SaveUser, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.SaveUser.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := g.delegate.SaveUser(user)
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.SaveUser, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.SaveUser].(func(user *unresilient.T, err error) error); fallback != nil {
+			return fallback(user, err)
+		}
+		return err
 	}
-	return err
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
+	}
+	return nonRetryableErr
 }
 `,
 					},
@@ -636,13 +1193,18 @@ type Service interface {
 package resilient
 
 import (
-	"context"
+	"errors"
 	goresilience "github.com/slok/goresilience"
 )
 
 type base struct {
-	errorPredicate func(string, error) bool
-	runnerFactory  runnerFactory
+	errorPredicate        func(string, error) bool
+	methodErrorPredicates map[string]func(error) bool
+	resultPredicates      map[string]interface{}
+	fallbacks             map[string]interface{}
+	noReturnErrorHandler  func(string, error)
+	runnerFactory         runnerFactory
+	instanceName          string
 }
 type runnerFactory interface {
 	GetRunner(name string) goresilience.Runner
@@ -651,6 +1213,12 @@ type runnerFactory interface {
 var RetryAllErrors = func(_ string, _ error) bool {
 	return true
 }
+var PanicOnNoReturnError = func(_ string, err error) {
+	panic(err)
+}
+
+// ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate
+var ErrRetryableResult = errors.New("retryable result")
 
 type Option func(*base)
 
@@ -659,23 +1227,58 @@ func WithRetryableErrorPredicate(fn func(string, error) bool) Option {
 		o.errorPredicate = fn
 	}
 }
-func (b *base) run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
-	return b.runnerFactory.GetRunner(name).Run(ctx, fn)
+func WithInstanceName(name string) Option {
+	return func(o *base) {
+		o.instanceName = name
+	}
+}
+func WithNoReturnErrorHandler(fn func(method string, err error)) Option {
+	return func(o *base) {
+		o.noReturnErrorHandler = fn
+	}
+}
+func WithMethodErrorPredicate(method string, fn func(error) bool) Option {
+	return func(o *base) {
+		if o.methodErrorPredicates == nil {
+			o.methodErrorPredicates = make(map[string]func(error) bool)
+		}
+		o.methodErrorPredicates[method] = fn
+	}
+}
+func (b *base) shouldRetry(method string, err error) bool {
+	if fn, ok := b.methodErrorPredicates[method]; ok {
+		return fn(err)
+	}
+	return b.errorPredicate(method, err)
+}
+func (b *base) runner(name string) goresilience.Runner {
+	if b.instanceName != "" {
+		name = b.instanceName + "." + name
+	}
+	return b.runnerFactory.GetRunner(name)
 }
 `,
 				Constants: `// Code generated by reinforcer, DO NOT EDIT.
 
 package resilient
 
-// GeneratedServiceMethods are the methods in GeneratedService
-var GeneratedServiceMethods = struct {
+// GeneratedServiceMethods are the methods in GeneratedService, these are the names of the runners used by each method
+var GeneratedServiceMethods = generatedServiceMethods{
+	ReceiveDir:     "GeneratedService.ReceiveDir",
+	SendDir:        "GeneratedService.SendDir",
+	SendReceiveDir: "GeneratedService.SendReceiveDir",
+}
+
+// generatedServiceMethods holds the names of the methods in GeneratedService
+type generatedServiceMethods struct {
 	ReceiveDir     string
 	SendDir        string
 	SendReceiveDir string
-}{
-	ReceiveDir:     "ReceiveDir",
-	SendDir:        "SendDir",
-	SendReceiveDir: "SendReceiveDir",
+}
+
+// All returns the names of all the methods in GeneratedService
+func (m generatedServiceMethods) All() []string {
+	return []string{m.ReceiveDir, m.SendDir, m.SendReceiveDir}
 }
 `,
 				Files: []*generator.GeneratedFile{
@@ -685,16 +1288,25 @@ var GeneratedServiceMethods = struct {
 
 package resilient
 
-import "context"
+import (
+	"context"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
+)
 
 type targetService interface {
-	ReceiveDir(arg0 <-chan error) error
-	SendDir(arg0 chan<- error) error
-	SendReceiveDir(arg0 chan error) error
+	ReceiveDir(myChan <-chan error) error
+	SendDir(myChan chan<- error) error
+	SendReceiveDir(myChan chan error) error
 }
 type GeneratedService struct {
 	*base
 	delegate targetService
+	runners  struct {
+		ReceiveDir     goresilience.Runner
+		SendDir        goresilience.Runner
+		SendReceiveDir goresilience.Runner
+	}
 }
 
 func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, options ...Option) *GeneratedService {
@@ -706,63 +1318,176 @@ func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, op
 	}
 	c := &GeneratedService{
 		base: &base{
-			errorPredicate: RetryAllErrors,
-			runnerFactory:  runnerFactory,
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
 		},
 		delegate: delegate,
 	}
 	for _, o := range options {
 		o(c.base)
 	}
+	c.runners.ReceiveDir = c.runner(GeneratedServiceMethods.ReceiveDir)
+	c.runners.SendDir = c.runner(GeneratedServiceMethods.SendDir)
+	c.runners.SendReceiveDir = c.runner(GeneratedServiceMethods.SendReceiveDir)
 	return c
 }
-func (g *GeneratedService) ReceiveDir(arg0 <-chan error) error {
+func WithGeneratedServiceReceiveDirFallback(fn func(myChan <-chan error, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.ReceiveDir] = fn
+	}
+}
+func WithGeneratedServiceSendDirFallback(fn func(myChan chan<- error, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.SendDir] = fn
+	}
+}
+func WithGeneratedServiceSendReceiveDirFallback(fn func(myChan chan error, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.SendReceiveDir] = fn
+	}
+}
+func WithGeneratedServiceFallbackDelegate(fallback targetService) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.ReceiveDir]; !ok {
+			o.fallbacks[GeneratedServiceMethods.ReceiveDir] = func(myChan <-chan error, _ error) error {
+				return fallback.ReceiveDir(myChan)
+			}
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.SendDir]; !ok {
+			o.fallbacks[GeneratedServiceMethods.SendDir] = func(myChan chan<- error, _ error) error {
+				return fallback.SendDir(myChan)
+			}
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.SendReceiveDir]; !ok {
+			o.fallbacks[GeneratedServiceMethods.SendReceiveDir] = func(myChan chan error, _ error) error {
+				return fallback.SendReceiveDir(myChan)
+			}
+		}
+	}
+}
+func (g *GeneratedService) ReceiveDir(myChan <-chan error) error {
 	var nonRetryableErr error
-	err := g.run(context.Background(), GeneratedServiceMethods.ReceiveDir, func(_ context.Context) error {
-		var err error
-		err = g.delegate.ReceiveDir(arg0)
-		if g.errorPredicate(GeneratedServiceMethods.ReceiveDir, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.ReceiveDir.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := g.delegate.ReceiveDir(myChan)
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.ReceiveDir, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.ReceiveDir].(func(myChan <-chan error, err error) error); fallback != nil {
+			return fallback(myChan, err)
+		}
+		return err
 	}
-	return err
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
+	}
+	return nonRetryableErr
 }
-func (g *GeneratedService) SendDir(arg0 chan<- error) error {
+func (g *GeneratedService) SendDir(myChan chan<- error) error {
 	var nonRetryableErr error
-	err := g.run(context.Background(), GeneratedServiceMethods.SendDir, func(_ context.Context) error {
-		var err error
-		err = g.delegate.SendDir(arg0)
-		if g.errorPredicate(GeneratedServiceMethods.SendDir, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.SendDir.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := g.delegate.SendDir(myChan)
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.SendDir, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.SendDir].(func(myChan chan<- error, err error) error); fallback != nil {
+			return fallback(myChan, err)
+		}
+		return err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
 	}
-	return err
+	return nonRetryableErr
 }
-func (g *GeneratedService) SendReceiveDir(arg0 chan error) error {
+func (g *GeneratedService) SendReceiveDir(myChan chan error) error {
 	var nonRetryableErr error
-	err := g.run(context.Background(), GeneratedServiceMethods.SendReceiveDir, func(_ context.Context) error {
-		var err error
-		err = g.delegate.SendReceiveDir(arg0)
-		if g.errorPredicate(GeneratedServiceMethods.SendReceiveDir, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.SendReceiveDir.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := g.delegate.SendReceiveDir(myChan)
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.SendReceiveDir, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.SendReceiveDir].(func(myChan chan error, err error) error); fallback != nil {
+			return fallback(myChan, err)
+		}
+		return err
 	}
-	return err
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
+	}
+	return nonRetryableErr
 }
 `,
 					},
@@ -770,15 +1495,27 @@ func (g *GeneratedService) SendReceiveDir(arg0 chan error) error {
 			},
 		},
 		{
-			name:                  "Unexported",
-			ignoreNoReturnMethods: true,
+			name:                  "Generic",
+			ignoreNoReturnMethods: false,
 			inputs: map[string]input{
-				"users_service.go": {
-					interfaceName: "service",
+				"repository.go": {
+					interfaceName: "Repository",
 					code: `package fake
 
-type service interface {
-	SayHello(name string) error
+import "context"
+
+type Number interface {
+	~int | ~int64 | float64
+}
+
+type Page[T any] struct {
+	Items []T
+}
+
+type Repository[T any, ID comparable, N Number] interface {
+	Get(ctx context.Context, id ID) (T, error)
+	List(ctx context.Context, limit N) (*Page[T], error)
+	Sum(values map[ID]N) (N, error)
 }
 `,
 				},
@@ -789,13 +1526,18 @@ type service interface {
 package resilient
 
 import (
-	"context"
+	"errors"
 	goresilience "github.com/slok/goresilience"
 )
 
 type base struct {
-	errorPredicate func(string, error) bool
-	runnerFactory  runnerFactory
+	errorPredicate        func(string, error) bool
+	methodErrorPredicates map[string]func(error) bool
+	resultPredicates      map[string]interface{}
+	fallbacks             map[string]interface{}
+	noReturnErrorHandler  func(string, error)
+	runnerFactory         runnerFactory
+	instanceName          string
 }
 type runnerFactory interface {
 	GetRunner(name string) goresilience.Runner
@@ -804,6 +1546,12 @@ type runnerFactory interface {
 var RetryAllErrors = func(_ string, _ error) bool {
 	return true
 }
+var PanicOnNoReturnError = func(_ string, err error) {
+	panic(err)
+}
+
+// ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate
+var ErrRetryableResult = errors.New("retryable result")
 
 type Option func(*base)
 
@@ -812,107 +1560,1650 @@ func WithRetryableErrorPredicate(fn func(string, error) bool) Option {
 		o.errorPredicate = fn
 	}
 }
-func (b *base) run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
-	return b.runnerFactory.GetRunner(name).Run(ctx, fn)
+func WithInstanceName(name string) Option {
+	return func(o *base) {
+		o.instanceName = name
+	}
+}
+func WithNoReturnErrorHandler(fn func(method string, err error)) Option {
+	return func(o *base) {
+		o.noReturnErrorHandler = fn
+	}
+}
+func WithMethodErrorPredicate(method string, fn func(error) bool) Option {
+	return func(o *base) {
+		if o.methodErrorPredicates == nil {
+			o.methodErrorPredicates = make(map[string]func(error) bool)
+		}
+		o.methodErrorPredicates[method] = fn
+	}
+}
+func (b *base) shouldRetry(method string, err error) bool {
+	if fn, ok := b.methodErrorPredicates[method]; ok {
+		return fn(err)
+	}
+	return b.errorPredicate(method, err)
+}
+func (b *base) runner(name string) goresilience.Runner {
+	if b.instanceName != "" {
+		name = b.instanceName + "." + name
+	}
+	return b.runnerFactory.GetRunner(name)
 }
 `,
 				Constants: `// Code generated by reinforcer, DO NOT EDIT.
 
 package resilient
 
-// GeneratedServiceMethods are the methods in GeneratedService
-var GeneratedServiceMethods = struct {
-	SayHello string
-}{
-	SayHello: "SayHello",
+// GeneratedRepositoryMethods are the methods in GeneratedRepository, these are the names of the runners used by each method
+var GeneratedRepositoryMethods = generatedRepositoryMethods{
+	Get:  "GeneratedRepository.Get",
+	List: "GeneratedRepository.List",
+	Sum:  "GeneratedRepository.Sum",
+}
+
+// generatedRepositoryMethods holds the names of the methods in GeneratedRepository
+type generatedRepositoryMethods struct {
+	Get  string
+	List string
+	Sum  string
+}
+
+// All returns the names of all the methods in GeneratedRepository
+func (m generatedRepositoryMethods) All() []string {
+	return []string{m.Get, m.List, m.Sum}
 }
 `,
 				Files: []*generator.GeneratedFile{
 					{
-						TypeName: "GeneratedService",
+						TypeName: "GeneratedRepository",
 						Contents: `// Code generated by reinforcer, DO NOT EDIT.
 
 package resilient
 
-import "context"
+import (
+	"context"
+	unresilient "github.com/csueiras/fake/unresilient"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
+)
 
-type targetService interface {
-	SayHello(arg0 string) error
+type targetRepository[T any, ID comparable, N unresilient.Number] interface {
+	Get(ctx context.Context, id ID) (T, error)
+	List(ctx context.Context, limit N) (*unresilient.Page[T], error)
+	Sum(values map[ID]N) (N, error)
 }
-type GeneratedService struct {
+type GeneratedRepository[T any, ID comparable, N unresilient.Number] struct {
 	*base
-	delegate targetService
+	delegate targetRepository[T, ID, N]
+	runners  struct {
+		Get  goresilience.Runner
+		List goresilience.Runner
+		Sum  goresilience.Runner
+	}
 }
 
-func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, options ...Option) *GeneratedService {
+func NewGeneratedRepository[T any, ID comparable, N unresilient.Number](delegate targetRepository[T, ID, N], runnerFactory runnerFactory, options ...Option) *GeneratedRepository[T, ID, N] {
 	if delegate == nil {
 		panic("provided nil delegate")
 	}
 	if runnerFactory == nil {
 		panic("provided nil runner factory")
 	}
-	c := &GeneratedService{
+	c := &GeneratedRepository[T, ID, N]{
 		base: &base{
-			errorPredicate: RetryAllErrors,
-			runnerFactory:  runnerFactory,
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
 		},
 		delegate: delegate,
 	}
 	for _, o := range options {
 		o(c.base)
 	}
+	c.runners.Get = c.runner(GeneratedRepositoryMethods.Get)
+	c.runners.List = c.runner(GeneratedRepositoryMethods.List)
+	c.runners.Sum = c.runner(GeneratedRepositoryMethods.Sum)
 	return c
 }
-func (g *GeneratedService) SayHello(arg0 string) error {
+func WithGeneratedRepositoryGetResultPredicate[T any, ID comparable, N unresilient.Number](fn func(T, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[GeneratedRepositoryMethods.Get] = fn
+	}
+}
+func WithGeneratedRepositoryGetFallback[T any, ID comparable, N unresilient.Number](fn func(ctx context.Context, id ID, err error) (T, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedRepositoryMethods.Get] = fn
+	}
+}
+func WithGeneratedRepositoryListResultPredicate[T any, ID comparable, N unresilient.Number](fn func(*unresilient.Page[T], error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[GeneratedRepositoryMethods.List] = fn
+	}
+}
+func WithGeneratedRepositoryListFallback[T any, ID comparable, N unresilient.Number](fn func(ctx context.Context, limit N, err error) (*unresilient.Page[T], error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedRepositoryMethods.List] = fn
+	}
+}
+func WithGeneratedRepositorySumResultPredicate[T any, ID comparable, N unresilient.Number](fn func(N, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[GeneratedRepositoryMethods.Sum] = fn
+	}
+}
+func WithGeneratedRepositorySumFallback[T any, ID comparable, N unresilient.Number](fn func(values map[ID]N, err error) (N, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedRepositoryMethods.Sum] = fn
+	}
+}
+func WithGeneratedRepositoryFallbackDelegate[T any, ID comparable, N unresilient.Number](fallback targetRepository[T, ID, N]) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[GeneratedRepositoryMethods.Get]; !ok {
+			o.fallbacks[GeneratedRepositoryMethods.Get] = func(ctx context.Context, id ID, _ error) (T, error) {
+				return fallback.Get(ctx, id)
+			}
+		}
+		if _, ok := o.fallbacks[GeneratedRepositoryMethods.List]; !ok {
+			o.fallbacks[GeneratedRepositoryMethods.List] = func(ctx context.Context, limit N, _ error) (*unresilient.Page[T], error) {
+				return fallback.List(ctx, limit)
+			}
+		}
+		if _, ok := o.fallbacks[GeneratedRepositoryMethods.Sum]; !ok {
+			o.fallbacks[GeneratedRepositoryMethods.Sum] = func(values map[ID]N, _ error) (N, error) {
+				return fallback.Sum(values)
+			}
+		}
+	}
+}
+func (g *GeneratedRepository[T, ID, N]) Get(ctx context.Context, id ID) (T, error) {
 	var nonRetryableErr error
-	err := g.run(context.Background(), GeneratedServiceMethods.SayHello, func(_ context.Context) error {
-		var err error
-		err = g.delegate.SayHello(arg0)
-		if g.errorPredicate(GeneratedServiceMethods.SayHello, err) {
+	var r0 T
+	type results struct {
+		attempt int64
+		r0      T
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.Get.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := g.delegate.Get(ctx, id)
+		if p, ok := g.resultPredicates[GeneratedRepositoryMethods.Get].(func(T, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		if err != nil && g.shouldRetry(GeneratedRepositoryMethods.Get, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedRepositoryMethods.Get].(func(ctx context.Context, id ID, err error) (T, error)); fallback != nil {
+			return fallback(ctx, id, err)
+		}
+		return *new(T), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
+	}
+	return r0, nonRetryableErr
+}
+func (g *GeneratedRepository[T, ID, N]) List(ctx context.Context, limit N) (*unresilient.Page[T], error) {
+	var nonRetryableErr error
+	var r0 *unresilient.Page[T]
+	type results struct {
+		attempt int64
+		r0      *unresilient.Page[T]
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.List.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := g.delegate.List(ctx, limit)
+		if p, ok := g.resultPredicates[GeneratedRepositoryMethods.List].(func(*unresilient.Page[T], error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
+			return err
+		}
+		if err != nil && g.shouldRetry(GeneratedRepositoryMethods.List, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedRepositoryMethods.List].(func(ctx context.Context, limit N, err error) (*unresilient.Page[T], error)); fallback != nil {
+			return fallback(ctx, limit, err)
+		}
+		return *new(*unresilient.Page[T]), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
+	}
+	return r0, nonRetryableErr
+}
+func (g *GeneratedRepository[T, ID, N]) Sum(values map[ID]N) (N, error) {
+	var nonRetryableErr error
+	var r0 N
+	type results struct {
+		attempt int64
+		r0      N
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.Sum.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := g.delegate.Sum(values)
+		if p, ok := g.resultPredicates[GeneratedRepositoryMethods.Sum].(func(N, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
+			return err
+		}
+		if err != nil && g.shouldRetry(GeneratedRepositoryMethods.Sum, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedRepositoryMethods.Sum].(func(values map[ID]N, err error) (N, error)); fallback != nil {
+			return fallback(values, err)
+		}
+		return *new(N), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
 	}
-	return err
+	return r0, nonRetryableErr
 }
 `,
 					},
 				},
 			},
 		},
-	}
+		{
+			name:                  "Preserves names",
+			ignoreNoReturnMethods: false,
+			inputs: map[string]input{
+				"users_service.go": {
+					interfaceName: "Service",
+					code: `package fake
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
+import "context"
 
-			ifaces := loadInterface(t, tt.inputs)
-			got, err := generator.Generate(generator.Config{
-				OutPkg:                "resilient",
-				Files:                 ifaces,
-				IgnoreNoReturnMethods: tt.ignoreNoReturnMethods,
-			})
+type User struct {
+	Name string
+}
 
-			if tt.wantErr {
-				require.Error(t, err)
-			} else {
-				require.NoError(t, err)
-				require.NotNil(t, got)
+type Service interface {
+	GetUser(c context.Context, id string) (user *User, err error)
+	Collisions(g int, ctx string, err error, nonRetryableErr bool, unresilient *User, r0 int, _ string, len int) (r1 int, _ error)
+}
+`,
+				},
+			},
+			outCode: &generator.Generated{
+				Common: `// Code generated by reinforcer, DO NOT EDIT.
 
-				require.Equal(t, tt.outCode.Constants, got.Constants)
-				require.Equal(t, tt.outCode.Common, got.Common)
+package resilient
 
-				for idx, genFile := range got.Files {
-					require.Equal(t, tt.outCode.Files[idx].TypeName, genFile.TypeName)
-					require.Equal(t, tt.outCode.Files[idx].Contents, genFile.Contents, "Contents don't match. Got:\n%s", genFile.Contents)
-				}
-				require.Equal(t, tt.outCode, got)
-			}
-		})
-	}
+import (
+	"errors"
+	goresilience "github.com/slok/goresilience"
+)
+
+type base struct {
+	errorPredicate        func(string, error) bool
+	methodErrorPredicates map[string]func(error) bool
+	resultPredicates      map[string]interface{}
+	fallbacks             map[string]interface{}
+	noReturnErrorHandler  func(string, error)
+	runnerFactory         runnerFactory
+	instanceName          string
+}
+type runnerFactory interface {
+	GetRunner(name string) goresilience.Runner
+}
+
+var RetryAllErrors = func(_ string, _ error) bool {
+	return true
+}
+var PanicOnNoReturnError = func(_ string, err error) {
+	panic(err)
+}
+
+// ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate
+var ErrRetryableResult = errors.New("retryable result")
+
+type Option func(*base)
+
+func WithRetryableErrorPredicate(fn func(string, error) bool) Option {
+	return func(o *base) {
+		o.errorPredicate = fn
+	}
+}
+func WithInstanceName(name string) Option {
+	return func(o *base) {
+		o.instanceName = name
+	}
+}
+func WithNoReturnErrorHandler(fn func(method string, err error)) Option {
+	return func(o *base) {
+		o.noReturnErrorHandler = fn
+	}
+}
+func WithMethodErrorPredicate(method string, fn func(error) bool) Option {
+	return func(o *base) {
+		if o.methodErrorPredicates == nil {
+			o.methodErrorPredicates = make(map[string]func(error) bool)
+		}
+		o.methodErrorPredicates[method] = fn
+	}
+}
+func (b *base) shouldRetry(method string, err error) bool {
+	if fn, ok := b.methodErrorPredicates[method]; ok {
+		return fn(err)
+	}
+	return b.errorPredicate(method, err)
+}
+func (b *base) runner(name string) goresilience.Runner {
+	if b.instanceName != "" {
+		name = b.instanceName + "." + name
+	}
+	return b.runnerFactory.GetRunner(name)
+}
+`,
+				Constants: `// Code generated by reinforcer, DO NOT EDIT.
+
+package resilient
+
+// GeneratedServiceMethods are the methods in GeneratedService, these are the names of the runners used by each method
+var GeneratedServiceMethods = generatedServiceMethods{
+	Collisions: "GeneratedService.Collisions",
+	GetUser:    "GeneratedService.GetUser",
+}
+
+// generatedServiceMethods holds the names of the methods in GeneratedService
+type generatedServiceMethods struct {
+	Collisions string
+	GetUser    string
+}
+
+// All returns the names of all the methods in GeneratedService
+func (m generatedServiceMethods) All() []string {
+	return []string{m.Collisions, m.GetUser}
+}
+`,
+				Files: []*generator.GeneratedFile{
+					{
+						TypeName: "GeneratedService",
+						Contents: `// Code generated by reinforcer, DO NOT EDIT.
+
+package resilient
+
+import (
+	"context"
+	unresilient "github.com/csueiras/fake/unresilient"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
+)
+
+type targetService interface {
+	Collisions(arg0 int, arg1 string, arg2 error, arg3 bool, arg4 *unresilient.User, arg5 int, arg6 string, arg7 int) (res0 int, _ error)
+	GetUser(ctx context.Context, id string) (user *unresilient.User, res1 error)
+}
+type GeneratedService struct {
+	*base
+	delegate targetService
+	runners  struct {
+		Collisions goresilience.Runner
+		GetUser    goresilience.Runner
+	}
+}
+
+func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, options ...Option) *GeneratedService {
+	if delegate == nil {
+		panic("provided nil delegate")
+	}
+	if runnerFactory == nil {
+		panic("provided nil runner factory")
+	}
+	c := &GeneratedService{
+		base: &base{
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
+		},
+		delegate: delegate,
+	}
+	for _, o := range options {
+		o(c.base)
+	}
+	c.runners.Collisions = c.runner(GeneratedServiceMethods.Collisions)
+	c.runners.GetUser = c.runner(GeneratedServiceMethods.GetUser)
+	return c
+}
+func WithGeneratedServiceCollisionsResultPredicate(fn func(int, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[GeneratedServiceMethods.Collisions] = fn
+	}
+}
+func WithGeneratedServiceCollisionsFallback(fn func(arg0 int, arg1 string, arg2 error, arg3 bool, arg4 *unresilient.User, arg5 int, arg6 string, arg7 int, err error) (int, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.Collisions] = fn
+	}
+}
+func WithGeneratedServiceGetUserResultPredicate(fn func(*unresilient.User, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[GeneratedServiceMethods.GetUser] = fn
+	}
+}
+func WithGeneratedServiceGetUserFallback(fn func(ctx context.Context, id string, err error) (*unresilient.User, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.GetUser] = fn
+	}
+}
+func WithGeneratedServiceFallbackDelegate(fallback targetService) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.Collisions]; !ok {
+			o.fallbacks[GeneratedServiceMethods.Collisions] = func(arg0 int, arg1 string, arg2 error, arg3 bool, arg4 *unresilient.User, arg5 int, arg6 string, arg7 int, _ error) (int, error) {
+				return fallback.Collisions(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+			}
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.GetUser]; !ok {
+			o.fallbacks[GeneratedServiceMethods.GetUser] = func(ctx context.Context, id string, _ error) (*unresilient.User, error) {
+				return fallback.GetUser(ctx, id)
+			}
+		}
+	}
+}
+func (g *GeneratedService) Collisions(arg0 int, arg1 string, arg2 error, arg3 bool, arg4 *unresilient.User, arg5 int, arg6 string, arg7 int) (res0 int, _ error) {
+	var nonRetryableErr error
+	var r0 int
+	type results struct {
+		attempt int64
+		r0      int
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.Collisions.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := g.delegate.Collisions(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+		if p, ok := g.resultPredicates[GeneratedServiceMethods.Collisions].(func(int, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
+			return err
+		}
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.Collisions, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.Collisions].(func(arg0 int, arg1 string, arg2 error, arg3 bool, arg4 *unresilient.User, arg5 int, arg6 string, arg7 int, err error) (int, error)); fallback != nil {
+			return fallback(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, err)
+		}
+		return *new(int), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
+	}
+	return r0, nonRetryableErr
+}
+func (g *GeneratedService) GetUser(ctx context.Context, id string) (user *unresilient.User, res1 error) {
+	var nonRetryableErr error
+	var r0 *unresilient.User
+	type results struct {
+		attempt int64
+		r0      *unresilient.User
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.GetUser.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := g.delegate.GetUser(ctx, id)
+		if p, ok := g.resultPredicates[GeneratedServiceMethods.GetUser].(func(*unresilient.User, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
+			return err
+		}
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.GetUser, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.GetUser].(func(ctx context.Context, id string, err error) (*unresilient.User, error)); fallback != nil {
+			return fallback(ctx, id, err)
+		}
+		return *new(*unresilient.User), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
+	}
+	return r0, nonRetryableErr
+}
+`,
+					},
+				},
+			},
+		},
+		{
+			name:                  "Unexported",
+			ignoreNoReturnMethods: true,
+			inputs: map[string]input{
+				"users_service.go": {
+					interfaceName: "service",
+					code: `package fake
+
+type service interface {
+	SayHello(name string) error
+}
+`,
+				},
+			},
+			outCode: &generator.Generated{
+				Common: `// Code generated by reinforcer, DO NOT EDIT.
+
+package resilient
+
+import (
+	"errors"
+	goresilience "github.com/slok/goresilience"
+)
+
+type base struct {
+	errorPredicate        func(string, error) bool
+	methodErrorPredicates map[string]func(error) bool
+	resultPredicates      map[string]interface{}
+	fallbacks             map[string]interface{}
+	noReturnErrorHandler  func(string, error)
+	runnerFactory         runnerFactory
+	instanceName          string
+}
+type runnerFactory interface {
+	GetRunner(name string) goresilience.Runner
+}
+
+var RetryAllErrors = func(_ string, _ error) bool {
+	return true
+}
+var PanicOnNoReturnError = func(_ string, err error) {
+	panic(err)
+}
+
+// ErrRetryableResult is the error returned when the result of the last attempt was rejected by a result predicate
+var ErrRetryableResult = errors.New("retryable result")
+
+type Option func(*base)
+
+func WithRetryableErrorPredicate(fn func(string, error) bool) Option {
+	return func(o *base) {
+		o.errorPredicate = fn
+	}
+}
+func WithInstanceName(name string) Option {
+	return func(o *base) {
+		o.instanceName = name
+	}
+}
+func WithNoReturnErrorHandler(fn func(method string, err error)) Option {
+	return func(o *base) {
+		o.noReturnErrorHandler = fn
+	}
+}
+func WithMethodErrorPredicate(method string, fn func(error) bool) Option {
+	return func(o *base) {
+		if o.methodErrorPredicates == nil {
+			o.methodErrorPredicates = make(map[string]func(error) bool)
+		}
+		o.methodErrorPredicates[method] = fn
+	}
+}
+func (b *base) shouldRetry(method string, err error) bool {
+	if fn, ok := b.methodErrorPredicates[method]; ok {
+		return fn(err)
+	}
+	return b.errorPredicate(method, err)
+}
+func (b *base) runner(name string) goresilience.Runner {
+	if b.instanceName != "" {
+		name = b.instanceName + "." + name
+	}
+	return b.runnerFactory.GetRunner(name)
+}
+`,
+				Constants: `// Code generated by reinforcer, DO NOT EDIT.
+
+package resilient
+
+// GeneratedServiceMethods are the methods in GeneratedService, these are the names of the runners used by each method
+var GeneratedServiceMethods = generatedServiceMethods{
+	SayHello: "GeneratedService.SayHello",
+}
+
+// generatedServiceMethods holds the names of the methods in GeneratedService
+type generatedServiceMethods struct {
+	SayHello string
+}
+
+// All returns the names of all the methods in GeneratedService
+func (m generatedServiceMethods) All() []string {
+	return []string{m.SayHello}
+}
+`,
+				Files: []*generator.GeneratedFile{
+					{
+						TypeName: "GeneratedService",
+						Contents: `// Code generated by reinforcer, DO NOT EDIT.
+
+package resilient
+
+import (
+	"context"
+	goresilience "github.com/slok/goresilience"
+	"sync/atomic"
+)
+
+type targetService interface {
+	SayHello(name string) error
+}
+type GeneratedService struct {
+	*base
+	delegate targetService
+	runners  struct {
+		SayHello goresilience.Runner
+	}
+}
+
+func NewGeneratedService(delegate targetService, runnerFactory runnerFactory, options ...Option) *GeneratedService {
+	if delegate == nil {
+		panic("provided nil delegate")
+	}
+	if runnerFactory == nil {
+		panic("provided nil runner factory")
+	}
+	c := &GeneratedService{
+		base: &base{
+			errorPredicate:       RetryAllErrors,
+			noReturnErrorHandler: PanicOnNoReturnError,
+			runnerFactory:        runnerFactory,
+		},
+		delegate: delegate,
+	}
+	for _, o := range options {
+		o(c.base)
+	}
+	c.runners.SayHello = c.runner(GeneratedServiceMethods.SayHello)
+	return c
+}
+func WithGeneratedServiceSayHelloFallback(fn func(name string, err error) error) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[GeneratedServiceMethods.SayHello] = fn
+	}
+}
+func WithGeneratedServiceFallbackDelegate(fallback targetService) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		if _, ok := o.fallbacks[GeneratedServiceMethods.SayHello]; !ok {
+			o.fallbacks[GeneratedServiceMethods.SayHello] = func(name string, _ error) error {
+				return fallback.SayHello(name)
+			}
+		}
+	}
+}
+func (g *GeneratedService) SayHello(name string) error {
+	var nonRetryableErr error
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := g.runners.SayHello.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := g.delegate.SayHello(name)
+		if err != nil && g.shouldRetry(GeneratedServiceMethods.SayHello, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		if fallback, _ := g.fallbacks[GeneratedServiceMethods.SayHello].(func(name string, err error) error); fallback != nil {
+			return fallback(name, err)
+		}
+		return err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
+	}
+	return nonRetryableErr
+}
+`,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ifaces := loadInterface(t, tt.inputs)
+			got, err := generator.Generate(generator.Config{
+				OutPkg:                "resilient",
+				Files:                 ifaces,
+				IgnoreNoReturnMethods: tt.ignoreNoReturnMethods,
+			})
+
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, got)
+
+				require.Equal(t, tt.outCode.Constants, got.Constants)
+				require.Equal(t, tt.outCode.Common, got.Common)
+
+				for idx, genFile := range got.Files {
+					require.Equal(t, tt.outCode.Files[idx].TypeName, genFile.TypeName)
+					require.Equal(t, tt.outCode.Files[idx].Contents, genFile.Contents, "Contents don't match. Got:\n%s", genFile.Contents)
+				}
+				require.Equal(t, tt.outCode, got)
+			}
+		})
+	}
+}
+
+// TestGenerator_Generate_RetrySafety compiles and runs the generated code to verify that the results of attempts that
+// are retried never leak into the values returned to the caller
+func TestGenerator_Generate_RetrySafety(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that compiles generated code in short mode")
+	}
+
+	serviceCode := `package fake
+
+import "context"
+
+type Service interface {
+	Get(ctx context.Context, id string) (string, int, error)
+}
+`
+	// The attempts given up on by the timeout middleware keep running, the race detector catches them committing their
+	// results concurrently with the caller
+	runGeneratedFiles(t, generator.GoResilienceRuntime, loadInterface(t, map[string]input{
+		"service.go": {interfaceName: "Service", code: serviceCode},
+	}), `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience"
+	rerrors "github.com/slok/goresilience/errors"
+	"github.com/slok/goresilience/retry"
+	"github.com/slok/goresilience/timeout"
+)
+
+var (
+	errRetryable    = errors.New("retryable")
+	errNonRetryable = errors.New("non-retryable")
+	errRejected     = errors.New("rejected")
+)
+
+// rejectAfter is a middleware that rejects the calls after the first n calls without executing them
+func rejectAfter(n int) goresilience.Middleware {
+	calls := 0
+	return func(next goresilience.Runner) goresilience.Runner {
+		return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+			calls++
+			if n > 0 && calls > n {
+				return errRejected
+			}
+			return next.Run(ctx, f)
+		})
+	}
+}
+
+type attempt struct {
+	value string
+	count int
+	err   error
+	delay time.Duration
+}
+
+type delegate struct {
+	mu       sync.Mutex
+	attempts []attempt
+}
+
+func (d *delegate) Get(_ context.Context, _ string) (string, int, error) {
+	d.mu.Lock()
+	a := d.attempts[0]
+	d.attempts = d.attempts[1:]
+	d.mu.Unlock()
+	time.Sleep(a.delay)
+	return a.value, a.count, a.err
+}
+
+func check(name string, attempts []attempt, rejectAfterCalls int, wantValue string, wantCount int, wantErr error) {
+	verify(name, attempts, runner.NewFactory(
+		retry.NewMiddleware(retry.Config{Times: len(attempts) - 1, WaitBase: time.Millisecond, DisableBackoff: true}),
+		rejectAfter(rejectAfterCalls),
+	), wantValue, wantCount, wantErr)
+}
+
+// checkTimeout verifies the results when the attempts are given up on by a timeout within the retries
+func checkTimeout(name string, attempts []attempt, wantValue string, wantCount int, wantErr error) {
+	verify(name, attempts, runner.NewFactory(
+		retry.NewMiddleware(retry.Config{Times: len(attempts) - 1, WaitBase: time.Millisecond, DisableBackoff: true}),
+		timeout.NewMiddleware(timeout.Config{Timeout: 20 * time.Millisecond}),
+	), wantValue, wantCount, wantErr)
+	// The attempts given up on finish after the call returned, waiting on them without synchronizing lets the race
+	// detector see their results being committed
+	time.Sleep(150 * time.Millisecond)
+}
+
+func verify(name string, attempts []attempt, factory *runner.Factory, wantValue string, wantCount int, wantErr error) {
+	svc := NewGeneratedService(&delegate{attempts: attempts}, factory, WithRetryableErrorPredicate(func(_ string, err error) bool {
+		return err != errNonRetryable
+	}))
+	value, count, err := svc.Get(context.Background(), "id")
+	if value != wantValue || count != wantCount || !errors.Is(err, wantErr) {
+		fmt.Printf("%s: got (%q, %d, %v), want (%q, %d, %v)\n", name, value, count, err, wantValue, wantCount, wantErr)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	check("final attempt rejected", []attempt{
+		{value: "partial", count: 1, err: errRetryable},
+		{value: "unreachable", count: 2, err: nil},
+	}, 1, "", 0, errRejected)
+	check("retries exhausted", []attempt{
+		{value: "partial", count: 1, err: errRetryable},
+		{value: "", count: 0, err: errRetryable},
+	}, 0, "", 0, errRetryable)
+	check("non-retryable error", []attempt{
+		{value: "partial", count: 1, err: errRetryable},
+		{value: "", count: 2, err: errNonRetryable},
+	}, 0, "", 2, errNonRetryable)
+	check("success", []attempt{
+		{value: "partial", count: 1, err: errRetryable},
+		{value: "final", count: 0, err: nil},
+	}, 0, "final", 0, nil)
+	checkTimeout("slow first attempt", []attempt{
+		{value: "late", count: 1, err: nil, delay: 100 * time.Millisecond},
+		{value: "final", count: 2, err: nil},
+	}, "final", 2, nil)
+	checkTimeout("slow attempt after retries", []attempt{
+		{value: "partial", count: 1, err: errRetryable},
+		{value: "late", count: 2, err: errNonRetryable, delay: 100 * time.Millisecond},
+	}, "", 0, rerrors.ErrTimeout)
+}
+`, "-race")
+}
+
+// TestGenerator_Generate_MethodErrorPredicate compiles and runs the generated code to verify that the per-method error
+// predicates take precedence over the predicate shared by all methods
+func TestGenerator_Generate_MethodErrorPredicate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that compiles generated code in short mode")
+	}
+
+	runGenerated(t, `package fake
+
+import "context"
+
+type Service interface {
+	Get(ctx context.Context, id string) (string, error)
+	List(ctx context.Context) ([]string, error)
+}
+`, `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience/retry"
+)
+
+var errNotFound = errors.New("not found")
+
+type delegate struct {
+	calls map[string]int
+}
+
+func (d *delegate) Get(_ context.Context, _ string) (string, error) {
+	d.calls["Get"]++
+	return "", errNotFound
+}
+
+func (d *delegate) List(_ context.Context) ([]string, error) {
+	d.calls["List"]++
+	return nil, errNotFound
+}
+
+func main() {
+	d := &delegate{calls: map[string]int{}}
+	factory := runner.NewFactory(retry.NewMiddleware(retry.Config{Times: 2, WaitBase: time.Millisecond, DisableBackoff: true}))
+	svc := NewGeneratedService(d, factory, WithMethodErrorPredicate(GeneratedServiceMethods.Get, func(err error) bool {
+		return !errors.Is(err, errNotFound)
+	}))
+
+	if _, err := svc.Get(context.Background(), "id"); !errors.Is(err, errNotFound) {
+		fmt.Printf("Get: got error %v, want %v\n", err, errNotFound)
+		os.Exit(1)
+	}
+	if _, err := svc.List(context.Background()); !errors.Is(err, errNotFound) {
+		fmt.Printf("List: got error %v, want %v\n", err, errNotFound)
+		os.Exit(1)
+	}
+	if d.calls["Get"] != 1 || d.calls["List"] != 3 {
+		fmt.Printf("got calls %v, want Get once and List three times\n", d.calls)
+		os.Exit(1)
+	}
+}
+`)
+}
+
+// TestGenerator_Generate_ResultPredicate compiles and runs the generated code to verify that the results rejected by a
+// result predicate are retried
+func TestGenerator_Generate_ResultPredicate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that compiles generated code in short mode")
+	}
+
+	runGenerated(t, `package fake
+
+import (
+	"context"
+	"net/http"
+)
+
+type Service interface {
+	Get(ctx context.Context, id string) (*http.Response, error)
+}
+`, `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience/retry"
+)
+
+type delegate struct {
+	statuses []int
+}
+
+func (d *delegate) Get(_ context.Context, _ string) (*http.Response, error) {
+	status := d.statuses[0]
+	d.statuses = d.statuses[1:]
+	return &http.Response{StatusCode: status}, nil
+}
+
+func check(name string, statuses []int, wantStatus int, wantErr error) {
+	factory := runner.NewFactory(retry.NewMiddleware(retry.Config{Times: len(statuses) - 1, WaitBase: time.Millisecond, DisableBackoff: true}))
+	svc := NewGeneratedService(&delegate{statuses: statuses}, factory, WithGeneratedServiceGetResultPredicate(func(res *http.Response, err error) bool {
+		return err == nil && res.StatusCode == http.StatusServiceUnavailable
+	}))
+	res, err := svc.Get(context.Background(), "id")
+	gotStatus := 0
+	if res != nil {
+		gotStatus = res.StatusCode
+	}
+	if gotStatus != wantStatus || !errors.Is(err, wantErr) {
+		fmt.Printf("%s: got (%d, %v), want (%d, %v)\n", name, gotStatus, err, wantStatus, wantErr)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	check("retried result", []int{http.StatusServiceUnavailable, http.StatusOK}, http.StatusOK, nil)
+	check("retries exhausted", []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}, 0, ErrRetryableResult)
+}
+`)
+}
+
+// TestGenerator_Generate_NoReturnErrorHandler compiles and runs the generated code to verify that the errors from methods
+// that don't return anything are given to the configured handler, and that these panic by default
+func TestGenerator_Generate_NoReturnErrorHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that compiles generated code in short mode")
+	}
+
+	runGenerated(t, `package fake
+
+import "context"
+
+type Service interface {
+	Notify(ctx context.Context, msg string)
+}
+`, `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+type delegate struct{}
+
+func (d *delegate) Notify(_ context.Context, _ string) {}
+
+func unavailable(_ goresilience.Runner) goresilience.Runner {
+	return goresilience.RunnerFunc(func(_ context.Context, _ goresilience.Func) error {
+		return errUnavailable
+	})
+}
+
+func main() {
+	factory := runner.NewFactory(unavailable)
+
+	var gotMethod string
+	var gotErr error
+	svc := NewGeneratedService(&delegate{}, factory, WithNoReturnErrorHandler(func(method string, err error) {
+		gotMethod, gotErr = method, err
+	}))
+	svc.Notify(context.Background(), "hello")
+	if gotMethod != GeneratedServiceMethods.Notify || !errors.Is(gotErr, errUnavailable) {
+		fmt.Printf("handler: got (%q, %v), want (%q, %v)\n", gotMethod, gotErr, GeneratedServiceMethods.Notify, errUnavailable)
+		os.Exit(1)
+	}
+
+	defer func() {
+		if r := recover(); r != errUnavailable {
+			fmt.Printf("default: got panic %v, want %v\n", r, errUnavailable)
+			os.Exit(1)
+		}
+	}()
+	NewGeneratedService(&delegate{}, factory).Notify(context.Background(), "hello")
+}
+`)
+}
+
+// TestGenerator_Generate_Fallback compiles and runs the generated code to verify that failed calls are served by the
+// fallbacks while non-retryable errors are returned as they are
+func TestGenerator_Generate_Fallback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that compiles generated code in short mode")
+	}
+
+	runGenerated(t, `package fake
+
+import "context"
+
+type Service interface {
+	Greet(ctx context.Context, name string) (string, error)
+	Farewell(ctx context.Context, name string) (string, error)
+}
+`, `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience/retry"
+)
+
+var (
+	errUnavailable = errors.New("unavailable")
+	errInvalid     = errors.New("invalid")
+)
+
+type failing struct {
+	err error
+}
+
+func (f *failing) Greet(_ context.Context, _ string) (string, error) {
+	return "", f.err
+}
+
+func (f *failing) Farewell(_ context.Context, _ string) (string, error) {
+	return "", f.err
+}
+
+type secondary struct{}
+
+func (s *secondary) Greet(_ context.Context, name string) (string, error) {
+	return "hi " + name, nil
+}
+
+func (s *secondary) Farewell(_ context.Context, name string) (string, error) {
+	return "bye " + name, nil
+}
+
+func check(name string, got string, gotErr error, want string, wantErr error) {
+	if got != want || !errors.Is(gotErr, wantErr) {
+		fmt.Printf("%s: got (%q, %v), want (%q, %v)\n", name, got, gotErr, want, wantErr)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	ctx := context.Background()
+	factory := runner.NewFactory(retry.NewMiddleware(retry.Config{Times: 1, WaitBase: time.Millisecond, DisableBackoff: true}))
+	greetFallback := WithGeneratedServiceGreetFallback(func(_ context.Context, name string, err error) (string, error) {
+		return "hello " + name + " (" + err.Error() + ")", nil
+	})
+	nonRetryable := WithRetryableErrorPredicate(func(_ string, err error) bool {
+		return err != errInvalid
+	})
+
+	svc := NewGeneratedService(&failing{err: errUnavailable}, factory, greetFallback)
+	got, err := svc.Greet(ctx, "bob")
+	check("method fallback", got, err, "hello bob (unavailable)", nil)
+	got, err = svc.Farewell(ctx, "bob")
+	check("no fallback", got, err, "", errUnavailable)
+
+	svc = NewGeneratedService(&failing{err: errInvalid}, factory, greetFallback, nonRetryable)
+	got, err = svc.Greet(ctx, "bob")
+	check("non-retryable error", got, err, "", errInvalid)
+
+	for _, options := range [][]Option{
+		{greetFallback, WithGeneratedServiceFallbackDelegate(&secondary{})},
+		{WithGeneratedServiceFallbackDelegate(&secondary{}), greetFallback},
+	} {
+		svc = NewGeneratedService(&failing{err: errUnavailable}, factory, options...)
+		got, err = svc.Greet(ctx, "bob")
+		check("method fallback over delegate", got, err, "hello bob (unavailable)", nil)
+		got, err = svc.Farewell(ctx, "bob")
+		check("delegate fallback", got, err, "bye bob", nil)
+	}
+}
+`)
+}
+
+// TestGenerator_Generate_RunnerNames compiles and runs the generated code to verify that the runners are named after the
+// type and the instance of the proxy
+func TestGenerator_Generate_RunnerNames(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that compiles generated code in short mode")
+	}
+
+	runGeneratedInputs(t, map[string]input{
+		"client.go": {
+			interfaceName: "Client",
+			code: `package fake
+
+import "context"
+
+type Client interface {
+	Get(ctx context.Context) error
+}
+`,
+		},
+		"other.go": {
+			interfaceName: "Other",
+			code: `package fake
+
+import "context"
+
+type Other interface {
+	Get(ctx context.Context) error
+}
+`,
+		},
+	}, `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience"
+)
+
+type recorder struct {
+	names []string
+}
+
+func (r *recorder) GetRunner(name string) goresilience.Runner {
+	r.names = append(r.names, name)
+	return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+		return f(ctx)
+	})
+}
+
+type delegate struct{}
+
+func (d *delegate) Get(_ context.Context) error {
+	return errors.New("failed")
+}
+
+func main() {
+	ctx := context.Background()
+	r := &recorder{}
+	var predicateMethods []string
+	predicate := WithRetryableErrorPredicate(func(method string, _ error) bool {
+		predicateMethods = append(predicateMethods, method)
+		return true
+	})
+
+	// The runners are resolved once when the proxy is created
+	c := NewGeneratedClient(&delegate{}, r)
+	_ = c.Get(ctx)
+	_ = c.Get(ctx)
+	_ = NewGeneratedOther(&delegate{}, r).Get(ctx)
+	_ = NewGeneratedClient(&delegate{}, r, WithInstanceName("primary"), predicate).Get(ctx)
+
+	want := []string{"GeneratedClient.Get", "GeneratedOther.Get", "primary.GeneratedClient.Get"}
+	if strings.Join(r.names, ",") != strings.Join(want, ",") {
+		fmt.Printf("got runners %v, want %v\n", r.names, want)
+		os.Exit(1)
+	}
+	if GeneratedClientMethods.Get != "GeneratedClient.Get" || GeneratedOtherMethods.Get != "GeneratedOther.Get" {
+		fmt.Printf("got constants %q and %q\n", GeneratedClientMethods.Get, GeneratedOtherMethods.Get)
+		os.Exit(1)
+	}
+	if len(predicateMethods) != 1 || predicateMethods[0] != GeneratedClientMethods.Get {
+		fmt.Printf("got predicate methods %v, want [%s]\n", predicateMethods, GeneratedClientMethods.Get)
+		os.Exit(1)
+	}
+
+	f := runner.NewFactory()
+	f.Warmup(GeneratedClientMethods.All()...)
+	if names := f.Names(); len(names) != 1 || names[0] != GeneratedClientMethods.Get {
+		fmt.Printf("got warmed up runners %v, want [%s]\n", names, GeneratedClientMethods.Get)
+		os.Exit(1)
+	}
+}
+`)
+}
+
+func TestGenerator_Generate_NativeRuntime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that compiles generated code in short mode")
+	}
+
+	runGeneratedRuntime(t, generator.NativeRuntime, map[string]input{
+		"service.go": {
+			interfaceName: "Service",
+			code: `package fake
+
+import "context"
+
+type Service interface {
+	GetName(ctx context.Context) (string, error)
+	Notify(ctx context.Context)
+}
+`,
+		},
+	}, `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/csueiras/reinforcer/pkg/resilience"
+)
+
+type delegate struct {
+	calls int
+}
+
+func (d *delegate) GetName(_ context.Context) (string, error) {
+	d.calls++
+	if d.calls < 3 {
+		return "", errors.New("failed")
+	}
+	return "reinforcer", nil
+}
+
+func (d *delegate) Notify(_ context.Context) {}
+
+func main() {
+	f := resilience.NewFactory(
+		resilience.Timeout(time.Second),
+		resilience.Retry(resilience.RetryConfig{Times: 3, WaitBase: time.Millisecond}),
+	)
+	d := &delegate{}
+	s := NewGeneratedService(d, f)
+	name, err := s.GetName(context.Background())
+	if err != nil || name != "reinforcer" || d.calls != 3 {
+		fmt.Printf("got %q, %v after %d calls\n", name, err, d.calls)
+		os.Exit(1)
+	}
+	s.Notify(context.Background())
+}
+`)
+}
+
+func TestGenerator_Generate_ConstantsOrder(t *testing.T) {
+	ifaces := loadInterface(t, map[string]input{
+		"beta.go": {
+			interfaceName: "Beta",
+			code: `package fake
+
+import "context"
+
+type Beta interface {
+	Get(ctx context.Context) error
+}
+`,
+		},
+		"alpha.go": {
+			interfaceName: "Alpha",
+			code: `package fake
+
+import "context"
+
+type Alpha interface {
+	Get(ctx context.Context) error
+}
+`,
+		},
+	})
+	require.Len(t, ifaces, 2)
+
+	generate := func(files ...*generator.FileConfig) string {
+		got, err := generator.Generate(generator.Config{OutPkg: "resilient", Files: files})
+		require.NoError(t, err)
+		return got.Constants
+	}
+	got := generate(ifaces[0], ifaces[1])
+	require.Equal(t, got, generate(ifaces[1], ifaces[0]))
+	require.Less(t, strings.Index(got, "GeneratedAlphaMethods"), strings.Index(got, "GeneratedBetaMethods"))
+}
+
+func TestGenerator_Generate_UnknownRuntime(t *testing.T) {
+	_, err := generator.Generate(generator.Config{
+		OutPkg:  "resilient",
+		Files:   []*generator.FileConfig{generator.NewFileConfig("Service", "GeneratedService", nil)},
+		Runtime: "hystrix",
+	})
+	require.EqualError(t, err, `unknown runtime "hystrix", must be one of "goresilience" or "native"`)
+}
+
+func TestGenerator_Generate_GRPCClient(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that compiles generated code in short mode")
+	}
+
+	svc, err := loader.DefaultLoader().LoadOne("google.golang.org/grpc/health/grpc_health_v1", "HealthClient", loader.PackageLoadMode)
+	require.NoError(t, err)
+	runGeneratedFiles(t, generator.GoResilienceRuntime, []*generator.FileConfig{
+		generator.NewGenericFileConfig("HealthClient", "GeneratedHealthClient", svc.TypeParams, svc.Methods),
+	}, `package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/csueiras/reinforcer/pkg/predicate/grpcstatus"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer fails the first checks of a service with the status code given as the service's name
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	attempts int
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	s.attempts++
+	_ = grpc.SetHeader(ctx, metadata.Pairs("attempt", strconv.Itoa(s.attempts)))
+	if s.attempts < 3 {
+		code, _ := strconv.Atoi(req.Service)
+		return nil, status.Error(codes.Code(code), "check failed")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func check(client grpc_health_v1.HealthClient, srv *healthServer, code codes.Code) (int, metadata.MD, error) {
+	srv.attempts = 0
+	var header metadata.MD
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: strconv.Itoa(int(code))}, grpc.Header(&header))
+	return srv.attempts, header, err
+}
+
+func main() {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := &healthServer{}
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, srv)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	defer s.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	f := runner.NewFactory(retry.NewMiddleware(retry.Config{Times: 3}))
+	client := NewGeneratedHealthClient(grpc_health_v1.NewHealthClient(conn), f, WithRetryableErrorPredicate(grpcstatus.Retryable))
+
+	// The call options are given to every attempt, the header is the one of the last attempt
+	attempts, header, err := check(client, srv, codes.Unavailable)
+	if err != nil || attempts != 3 || header.Get("attempt")[0] != "3" {
+		fmt.Printf("got %v after %d attempts with header %v, want a successful check after 3 attempts\n", err, attempts, header)
+		os.Exit(1)
+	}
+
+	attempts, _, err = check(client, srv, codes.InvalidArgument)
+	if status.Code(err) != codes.InvalidArgument || attempts != 1 {
+		fmt.Printf("got %v after %d attempts, want InvalidArgument after 1 attempt\n", err, attempts)
+		os.Exit(1)
+	}
+}
+`)
+}
+
+// runGenerated generates the proxy for the interface named Service found in the given source into the main package, and
+// runs it along with the given main file
+func runGenerated(t *testing.T, serviceCode, mainCode string) {
+	runGeneratedInputs(t, map[string]input{
+		"service.go": {
+			interfaceName: "Service",
+			code:          serviceCode,
+		},
+	}, mainCode)
+}
+
+// runGeneratedInputs generates the proxies for the given inputs into the main package, and runs them along with the given
+// main file
+func runGeneratedInputs(t *testing.T, inputs map[string]input, mainCode string) {
+	runGeneratedRuntime(t, generator.GoResilienceRuntime, inputs, mainCode)
+}
+
+// runGeneratedRuntime generates the proxies for the given inputs targeting the given runtime into the main package, and
+// runs them along with the given main file
+func runGeneratedRuntime(t *testing.T, runtime generator.Runtime, inputs map[string]input, mainCode string) {
+	runGeneratedFiles(t, runtime, loadInterface(t, inputs), mainCode)
+}
+
+// runGeneratedFiles generates the proxies for the given types targeting the given runtime into the main package, and
+// runs them along with the given main file using the given flags of go run (e.g. -race)
+func runGeneratedFiles(t *testing.T, runtime generator.Runtime, fileConfigs []*generator.FileConfig, mainCode string, runFlags ...string) {
+	got, err := generator.Generate(generator.Config{
+		OutPkg:  "main",
+		Files:   fileConfigs,
+		Runtime: runtime,
+	})
+	require.NoError(t, err)
+
+	// Directories prefixed with an underscore are ignored by the go tool's package patterns (e.g. ./...)
+	dir, err := os.MkdirTemp(".", "_generated")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	files := map[string]string{
+		"reinforcer_common.go":    got.Common,
+		"reinforcer_constants.go": got.Constants,
+		"main.go":                 mainCode,
+	}
+	for _, f := range got.Files {
+		files[strings.ToLower(f.TypeName)+".go"] = f.Contents
+	}
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+
+	args := append(append([]string{"run"}, runFlags...), "./"+dir)
+	out, err := exec.Command("go", args...).CombinedOutput()
+	require.NoError(t, err, "generated code failed:\n%s", out)
 }
 
 func loadInterface(t *testing.T, filesCode map[string]input) []*generator.FileConfig {
@@ -940,8 +3231,9 @@ func loadInterface(t *testing.T, filesCode map[string]input) []*generator.FileCo
 	for _, in := range filesCode {
 		svc, err := l.LoadOne(pkg, in.interfaceName, loader.PackageLoadMode)
 		require.NoError(t, err)
-		loadedTypes = append(loadedTypes, generator.NewFileConfig(in.interfaceName,
+		loadedTypes = append(loadedTypes, generator.NewGenericFileConfig(in.interfaceName,
 			fmt.Sprintf("Generated%s", strings.Title(in.interfaceName)),
+			svc.TypeParams,
 			svc.Methods,
 		))
 	}
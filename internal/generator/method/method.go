@@ -6,15 +6,16 @@ import (
 	"github.com/dave/jennifer/jen"
 	"github.com/pkg/errors"
 	"go/types"
+	"path"
+	"strings"
 )
 
 const (
 	ctxVarName = "ctx"
 )
 
-type named interface {
-	Name() string
-}
+// reservedNames are the identifiers used by the generated code that parameters and results must not shadow
+var reservedNames = []string{ctxVarName, "err", "nonRetryableErr", "fallback", "results", "attempts", "attempt", "latest", "context", "atomic"}
 
 // Method holds all of the data for code generation on a specific method signature
 type Method struct {
@@ -24,9 +25,69 @@ type Method struct {
 	HasVariadic           bool
 	ParameterNames        []string
 	ParametersNameAndType []jen.Code
-	ReturnTypes           []jen.Code
-	ContextParameter      *int
-	ReturnErrorIndex      *int
+	// ParametersNameAndSliceType are the same as ParametersNameAndType but a variadic parameter is declared as a slice,
+	// these can be used in signatures that declare parameters after the ones of the method
+	ParametersNameAndSliceType []jen.Code
+	ReturnTypes                []jen.Code
+	ResultNames                []string
+	ResultsNameAndType         []jen.Code
+	ContextParameter           *int
+	ReturnErrorIndex           *int
+	// HasCallOptions is true when the variadic parameter holds gRPC call options, as in the methods of generated gRPC
+	// clients
+	HasCallOptions bool
+
+	// signature is the source signature this method was parsed from
+	signature *types.Signature
+}
+
+// TypeParam holds the data for code generation of a type parameter declared by a generic type
+type TypeParam struct {
+	Name       string
+	Constraint jen.Code
+}
+
+// ParseTypeParams parses the given types.TypeParamList into the TypeParams that are required to redeclare them
+func ParseTypeParams(typeParams *types.TypeParamList) ([]*TypeParam, error) {
+	var params []*TypeParam
+	for i := 0; i < typeParams.Len(); i++ {
+		tp := typeParams.At(i)
+		constraint, err := toType(tp.Constraint(), false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert constraint of type parameter %s; error=%w", tp.Obj().Name(), err)
+		}
+		params = append(params, &TypeParam{
+			Name:       tp.Obj().Name(),
+			Constraint: constraint,
+		})
+	}
+	return params, nil
+}
+
+// TypeParamsDecl generates the type parameter declaration list (e.g. [K comparable, V any]), nothing is generated for
+// non-generic types
+func TypeParamsDecl(typeParams []*TypeParam) *jen.Statement {
+	if len(typeParams) == 0 {
+		return jen.Null()
+	}
+	var decl []jen.Code
+	for _, tp := range typeParams {
+		decl = append(decl, jen.Id(tp.Name).Add(tp.Constraint))
+	}
+	return jen.Types(decl...)
+}
+
+// TypeParamsRef generates the type argument list that references the declared type parameters (e.g. [K, V]), nothing
+// is generated for non-generic types
+func TypeParamsRef(typeParams []*TypeParam) *jen.Statement {
+	if len(typeParams) == 0 {
+		return jen.Null()
+	}
+	var ref []jen.Code
+	for _, tp := range typeParams {
+		ref = append(ref, jen.Id(tp.Name))
+	}
+	return jen.Types(ref...)
 }
 
 // ConstantRef is the reference to the constant for this method's name
@@ -35,6 +96,11 @@ func (m *Method) ConstantRef(parentTypeName string) jen.Code {
 	return jen.Id(constantsStructName).Dot(m.Name)
 }
 
+// RunnerRef is the reference to the runner resolved for this method when the proxy with the given receiver was created
+func (m *Method) RunnerRef(receiverName string) *jen.Statement {
+	return jen.Id(receiverName).Dot("runners").Dot(m.Name)
+}
+
 // ContextParam generates the param name and type for a context arg for the given method
 func (m *Method) ContextParam() (ctxParamName string, ctxParam jen.Code) {
 	ctxParamName = ctxVarName
@@ -73,13 +139,29 @@ func MustParseMethod(name string, signature *types.Signature) *Method {
 
 // ParseMethod parses the given types.Signature and generates a Method
 func ParseMethod(name string, signature *types.Signature) (*Method, error) {
+	return parseMethod(name, signature, nil)
+}
+
+// WithReservedNames creates a copy of this method where the parameters and results that collide with any of the given
+// names (e.g. the receiver's name of the generated method) are renamed
+func (m *Method) WithReservedNames(names ...string) (*Method, error) {
+	if m.signature == nil {
+		return m, nil
+	}
+	return parseMethod(m.Name, m.signature, names)
+}
+
+func parseMethod(name string, signature *types.Signature, reserved []string) (*Method, error) {
 	m := &Method{
 		Name:             name,
 		ReturnErrorIndex: nil,
 		ContextParameter: nil,
 		HasVariadic:      signature.Variadic(),
+		signature:        signature,
 	}
 
+	paramNames, resultNames := varNames(signature, reserved)
+
 	isVariadic := signature.Variadic()
 	numParams := signature.Params().Len()
 	for i, lastIndex := 0, numParams-1; i < numParams; i++ {
@@ -89,15 +171,25 @@ func ParseMethod(name string, signature *types.Signature) (*Method, error) {
 			m.ContextParameter = new(int)
 			*m.ContextParameter = i
 			m.ParametersNameAndType = append(m.ParametersNameAndType, jen.Id(ctxVarName).Add(jen.Qual("context", "Context")))
+			m.ParametersNameAndSliceType = append(m.ParametersNameAndSliceType, jen.Id(ctxVarName).Add(jen.Qual("context", "Context")))
 			m.ParameterNames = append(m.ParameterNames, ctxVarName)
 		} else {
-			paramName := fmt.Sprintf("arg%d", i)
+			paramName := paramNames[i]
 
 			paramType, err := toType(param.Type(), isVariadic && i == lastIndex)
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert type=%v; error=%w", param.Type(), err)
 			}
 			m.ParametersNameAndType = append(m.ParametersNameAndType, jen.Id(paramName).Add(paramType))
+			if isVariadic && i == lastIndex {
+				if paramType, err = toType(param.Type(), false); err != nil {
+					return nil, fmt.Errorf("failed to convert type=%v; error=%w", param.Type(), err)
+				}
+				if slice, ok := param.Type().(*types.Slice); ok && rtypes.IsGRPCCallOptionType(slice.Elem()) {
+					m.HasCallOptions = true
+				}
+			}
+			m.ParametersNameAndSliceType = append(m.ParametersNameAndSliceType, jen.Id(paramName).Add(paramType))
 			m.ParameterNames = append(m.ParameterNames, paramName)
 		}
 	}
@@ -105,7 +197,13 @@ func ParseMethod(name string, signature *types.Signature) (*Method, error) {
 		res := signature.Results().At(i)
 		resType, err := toType(res.Type(), false)
 		if err != nil {
-			panic(err)
+			return nil, fmt.Errorf("failed to convert type=%v; error=%w", res.Type(), err)
+		}
+		if resultNames != nil {
+			m.ResultNames = append(m.ResultNames, resultNames[i])
+			m.ResultsNameAndType = append(m.ResultsNameAndType, jen.Id(resultNames[i]).Add(resType))
+		} else {
+			m.ResultsNameAndType = append(m.ResultsNameAndType, resType)
 		}
 		if rtypes.IsErrorType(res.Type()) {
 			if m.ReturnErrorIndex != nil {
@@ -120,6 +218,95 @@ func ParseMethod(name string, signature *types.Signature) (*Method, error) {
 	return m, nil
 }
 
+// varNames determines the names of the parameters and results of the signature, the source names are preserved unless
+// they are missing or would collide with identifiers used by the generated code. Result names are nil when the
+// signature's results are unnamed.
+func varNames(signature *types.Signature, reserved []string) (paramNames []string, resultNames []string) {
+	params := signature.Params()
+	results := signature.Results()
+
+	taken := make(map[string]struct{})
+	for _, name := range reservedNames {
+		taken[name] = struct{}{}
+	}
+	for _, name := range reserved {
+		taken[name] = struct{}{}
+	}
+	for i := 0; i < results.Len(); i++ {
+		// Generated code holds the delegate's results in r0, r1, ... and the results of each attempt in a0, a1, ...
+		taken[fmt.Sprintf("r%d", i)] = struct{}{}
+		taken[fmt.Sprintf("a%d", i)] = struct{}{}
+	}
+	for _, name := range importNames(signature) {
+		taken[name] = struct{}{}
+	}
+
+	isUsable := func(name string) bool {
+		if name == "" || name == "_" || types.Universe.Lookup(name) != nil {
+			return false
+		}
+		_, ok := taken[name]
+		return !ok
+	}
+	uniqueName := func(base string) string {
+		name := base
+		for suffix := 1; !isUsable(name); suffix++ {
+			name = fmt.Sprintf("%s_%d", base, suffix)
+		}
+		return name
+	}
+
+	// Source names are claimed first so that the generated names never collide with them
+	paramNames = make([]string, params.Len())
+	for i := 0; i < params.Len(); i++ {
+		if name := params.At(i).Name(); isUsable(name) {
+			paramNames[i] = name
+			taken[name] = struct{}{}
+		}
+	}
+	namedResults := results.Len() > 0 && results.At(0).Name() != ""
+	if namedResults {
+		resultNames = make([]string, results.Len())
+		for i := 0; i < results.Len(); i++ {
+			if name := results.At(i).Name(); name == "_" || isUsable(name) {
+				resultNames[i] = name
+				taken[name] = struct{}{}
+			}
+		}
+	}
+
+	for i := range paramNames {
+		if paramNames[i] == "" {
+			paramNames[i] = uniqueName(fmt.Sprintf("arg%d", i))
+			taken[paramNames[i]] = struct{}{}
+		}
+	}
+	for i := range resultNames {
+		if resultNames[i] == "" {
+			resultNames[i] = uniqueName(fmt.Sprintf("res%d", i))
+			taken[resultNames[i]] = struct{}{}
+		}
+	}
+	return paramNames, resultNames
+}
+
+// importNames returns the names that the packages referenced by the signature's types may be imported as, which is
+// either the package's name or the alias derived from its path
+func importNames(signature *types.Signature) []string {
+	var names []string
+	types.TypeString(signature, func(pkg *types.Package) string {
+		alias := strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return -1
+		}, strings.ToLower(path.Base(pkg.Path())))
+		names = append(names, pkg.Name(), alias)
+		return pkg.Name()
+	})
+	return names
+}
+
 // variadicToType generates the representation for a variadic type "...MyType"
 func variadicToType(t types.Type) (jen.Code, error) {
 	sliceType, ok := t.(*types.Slice)
@@ -141,12 +328,25 @@ func toType(t types.Type, variadic bool) (jen.Code, error) {
 
 	switch v := t.(type) {
 	case *types.Basic:
+		if v.Kind() == types.UnsafePointer {
+			return jen.Qual("unsafe", "Pointer"), nil
+		}
 		return jen.Id(v.Name()), nil
+	case *types.Array:
+		elemType, err := toType(v.Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		return jen.Index(jen.Lit(int(v.Len()))).Add(elemType), nil
 	case *types.Chan:
 		rt, err := toType(v.Elem(), false)
 		if err != nil {
 			return nil, err
 		}
+		if elemChan, ok := v.Elem().(*types.Chan); ok && v.Dir() == types.SendRecv && elemChan.Dir() == types.RecvOnly {
+			// chan (<-chan T) would otherwise be parsed as chan<- (chan T)
+			rt = jen.Parens(rt)
+		}
 		switch v.Dir() {
 		case types.SendRecv:
 			return jen.Chan().Add(rt), nil
@@ -157,21 +357,59 @@ func toType(t types.Type, variadic bool) (jen.Code, error) {
 		}
 	case *types.Named:
 		typeName := v.Obj()
-		if _, ok := v.Underlying().(*types.Interface); ok {
-			if typeName.Pkg() != nil {
-				pkgPath := typeName.Pkg().Path()
-				return jen.Qual(
-					pkgPath,
-					typeName.Name(),
-				), nil
-			}
+		var typ *jen.Statement
+		if typeName.Pkg() == nil {
+			// Predeclared types such as error and comparable
+			typ = jen.Id(typeName.Name())
+		} else {
+			typ = jen.Qual(
+				typeName.Pkg().Path(),
+				typeName.Name(),
+			)
+		}
+		typeArgs, err := typeListToTypes(v.TypeArgs())
+		if err != nil {
+			return nil, err
+		}
+		if len(typeArgs) > 0 {
+			typ = typ.Types(typeArgs...)
+		}
+		return typ, nil
+	case *types.Alias:
+		typeName := v.Obj()
+		if typeName.Pkg() == nil {
+			// Predeclared alias such as any
 			return jen.Id(typeName.Name()), nil
 		}
-		pkgPath := typeName.Pkg().Path()
-		return jen.Qual(
-			pkgPath,
-			typeName.Name(),
-		), nil
+		if !typeName.Exported() {
+			// Unexported aliases can't be referenced from the generated package, use the aliased type instead
+			return toType(v.Rhs(), false)
+		}
+		typ := jen.Qual(typeName.Pkg().Path(), typeName.Name())
+		typeArgs, err := typeListToTypes(v.TypeArgs())
+		if err != nil {
+			return nil, err
+		}
+		if len(typeArgs) > 0 {
+			typ = typ.Types(typeArgs...)
+		}
+		return typ, nil
+	case *types.TypeParam:
+		return jen.Id(v.Obj().Name()), nil
+	case *types.Union:
+		var terms []jen.Code
+		for i := 0; i < v.Len(); i++ {
+			term := v.Term(i)
+			termType, err := toType(term.Type(), false)
+			if err != nil {
+				return nil, err
+			}
+			if term.Tilde() {
+				termType = jen.Op("~").Add(termType)
+			}
+			terms = append(terms, termType)
+		}
+		return jen.Union(terms...), nil
 	case *types.Pointer:
 		rt, err := toType(v.Elem(), false)
 		if err != nil {
@@ -179,15 +417,15 @@ func toType(t types.Type, variadic bool) (jen.Code, error) {
 		}
 		return jen.Op("*").Add(rt), nil
 	case *types.Interface:
-		return jen.Id("interface{}"), nil
+		return interfaceToType(v)
+	case *types.Struct:
+		return structToType(v)
 	case *types.Slice:
 		elemType, err := toType(v.Elem(), false)
 		if err != nil {
 			return nil, err
 		}
 		return jen.Index().Add(elemType), nil
-	case named:
-		return jen.Id(v.Name()), nil
 	case *types.Map:
 		keyType, err := toType(v.Key(), false)
 		if err != nil {
@@ -199,35 +437,124 @@ func toType(t types.Type, variadic bool) (jen.Code, error) {
 		}
 		return jen.Map(keyType).Add(elemType), nil
 	case *types.Signature:
-		fnVariadic := v.Variadic()
-		var paramTypes []jen.Code
-		lastIndex := v.Params().Len() - 1
-		for p := 0; p < v.Params().Len(); p++ {
-			paramType := v.Params().At(p).Type()
-			tt, err := toType(paramType, lastIndex == p && fnVariadic)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to convert type %v", paramType)
-			}
-			paramTypes = append(paramTypes, tt)
+		params, results, err := signatureToTypes(v)
+		if err != nil {
+			return nil, err
 		}
+		return withResults(jen.Func().Params(params...), results), nil
+	default:
+		return nil, fmt.Errorf("type not handled: %T", v)
+	}
+}
 
-		var returnTypes []jen.Code
-		for r := 0; r < v.Results().Len(); r++ {
-			returnType := v.Results().At(r).Type()
-			tt, err := toType(returnType, false)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to convert type %v", returnType)
-			}
-			returnTypes = append(returnTypes, tt)
+// typeListToTypes generates the representation for the type arguments of an instantiated type
+func typeListToTypes(typeList *types.TypeList) ([]jen.Code, error) {
+	var typeArgs []jen.Code
+	for i := 0; i < typeList.Len(); i++ {
+		typeArg, err := toType(typeList.At(i), false)
+		if err != nil {
+			return nil, err
 		}
-		if len(returnTypes) == 0 {
-			return jen.Func().Params(paramTypes...), nil
+		typeArgs = append(typeArgs, typeArg)
+	}
+	return typeArgs, nil
+}
+
+// signatureToTypes generates the representation for the parameter types and the result types of a signature
+func signatureToTypes(signature *types.Signature) ([]jen.Code, []jen.Code, error) {
+	fnVariadic := signature.Variadic()
+	var paramTypes []jen.Code
+	lastIndex := signature.Params().Len() - 1
+	for p := 0; p < signature.Params().Len(); p++ {
+		paramType := signature.Params().At(p).Type()
+		tt, err := toType(paramType, lastIndex == p && fnVariadic)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to convert type %v", paramType)
 		}
-		if len(returnTypes) > 1 {
-			return jen.Func().Params(paramTypes...).Parens(jen.List(returnTypes...)), nil
+		paramTypes = append(paramTypes, tt)
+	}
+
+	var returnTypes []jen.Code
+	for r := 0; r < signature.Results().Len(); r++ {
+		returnType := signature.Results().At(r).Type()
+		tt, err := toType(returnType, false)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to convert type %v", returnType)
 		}
-		return jen.Func().Params(paramTypes...).Add(returnTypes[0]), nil
-	default:
-		return nil, fmt.Errorf("type not handled: %T", v)
+		returnTypes = append(returnTypes, tt)
+	}
+	return paramTypes, returnTypes, nil
+}
+
+// withResults appends the result types to the given function or method declaration
+func withResults(fn *jen.Statement, returnTypes []jen.Code) *jen.Statement {
+	if len(returnTypes) == 0 {
+		return fn
+	}
+	if len(returnTypes) > 1 {
+		return fn.Parens(jen.List(returnTypes...))
+	}
+	return fn.Add(returnTypes[0])
+}
+
+// interfaceToType generates the representation for an interface literal, including its embedded types and methods.
+// Interfaces with unexported methods can't be reproduced, the same literal written in another package is a different
+// type.
+func interfaceToType(iface *types.Interface) (jen.Code, error) {
+	if iface.IsImplicit() {
+		// Implicit interfaces are constraints written without the interface keyword (e.g. [T ~int | ~string])
+		return toType(iface.EmbeddedType(0), false)
+	}
+	if iface.NumEmbeddeds() == 0 && iface.NumExplicitMethods() == 0 {
+		return jen.Id("interface{}"), nil
+	}
+
+	var elems []jen.Code
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		embedded, err := toType(iface.EmbeddedType(i), false)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, embedded)
+	}
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		meth := iface.ExplicitMethod(i)
+		if !meth.Exported() {
+			return nil, fmt.Errorf("interface with unexported method %s can't be referenced outside of its package", meth.Name())
+		}
+		params, results, err := signatureToTypes(meth.Type().(*types.Signature))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert method %s", meth.Name())
+		}
+		elems = append(elems, withResults(jen.Id(meth.Name()).Params(params...), results))
+	}
+	return jen.Interface(elems...), nil
+}
+
+// structToType generates the representation for a struct literal, including its embedded fields and tags. Structs with
+// unexported fields can't be reproduced, the same literal written in another package is a different type.
+func structToType(strct *types.Struct) (jen.Code, error) {
+	var fields []jen.Code
+	for i := 0; i < strct.NumFields(); i++ {
+		field := strct.Field(i)
+		if !field.Exported() {
+			return nil, fmt.Errorf("struct with unexported field %s can't be referenced outside of its package", field.Name())
+		}
+		fieldType, err := toType(field.Type(), false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert field %s", field.Name())
+		}
+		var f *jen.Statement
+		if field.Embedded() {
+			f = jen.Add(fieldType)
+		} else {
+			f = jen.Id(field.Name()).Add(fieldType)
+		}
+		if tag := strct.Tag(i); tag != "" {
+			// The tag is emitted as written, keeping the order of its keys and any unconventional content
+			f = f.Lit(tag)
+		}
+		fields = append(fields, f)
 	}
+	return jen.Struct(fields...), nil
 }
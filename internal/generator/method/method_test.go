@@ -48,8 +48,8 @@ func TestNewMethod(t *testing.T) {
 			want: &method.Method{
 				Name:                  "Fn",
 				HasContext:            false,
-				ParameterNames:        []string{"arg0"},
-				ParametersNameAndType: []jen.Code{jen.Id("arg0").Add(jen.Id("string"))},
+				ParameterNames:        []string{"myArg"},
+				ParametersNameAndType: []jen.Code{jen.Id("myArg").Add(jen.Id("string"))},
 			},
 		},
 		{
@@ -61,8 +61,8 @@ func TestNewMethod(t *testing.T) {
 			want: &method.Method{
 				Name:                  "Fn",
 				HasContext:            false,
-				ParameterNames:        []string{"arg0"},
-				ParametersNameAndType: []jen.Code{jen.Id("arg0").Add(jen.Op("...").Add(jen.Id("string")))},
+				ParameterNames:        []string{"args"},
+				ParametersNameAndType: []jen.Code{jen.Id("args").Add(jen.Op("...").Add(jen.Id("string")))},
 			},
 		},
 		{
@@ -76,8 +76,8 @@ func TestNewMethod(t *testing.T) {
 				Name:                  "Fn",
 				HasContext:            false,
 				HasVariadic:           true,
-				ParameterNames:        []string{"arg0", "arg1"},
-				ParametersNameAndType: []jen.Code{jen.Id("arg0").Add(jen.Id("string")), jen.Id("arg1").Add(jen.Op("...").Add(jen.Id("string")))},
+				ParameterNames:        []string{"arg0", "args"},
+				ParametersNameAndType: []jen.Code{jen.Id("arg0").Add(jen.Id("string")), jen.Id("args").Add(jen.Op("...").Add(jen.Id("string")))},
 			},
 		},
 		{
@@ -94,8 +94,8 @@ func TestNewMethod(t *testing.T) {
 				HasContext:            true,
 				ContextParameter:      zero,
 				ReturnsError:          false,
-				ParameterNames:        []string{"ctx", "arg1"},
-				ParametersNameAndType: []jen.Code{jen.Id("ctx").Add(jen.Qual("context", "Context")), jen.Id("arg1").Add(jen.Id("string"))},
+				ParameterNames:        []string{"ctx", "myArg"},
+				ParametersNameAndType: []jen.Code{jen.Id("ctx").Add(jen.Qual("context", "Context")), jen.Id("myArg").Add(jen.Id("string"))},
 				ReturnTypes:           nil,
 			},
 		},
@@ -112,8 +112,8 @@ func TestNewMethod(t *testing.T) {
 				Name:                  "Fn",
 				HasContext:            true,
 				ReturnsError:          true,
-				ParameterNames:        []string{"ctx", "arg1"},
-				ParametersNameAndType: []jen.Code{jen.Id("ctx").Add(jen.Qual("context", "Context")), jen.Id("arg1").Add(jen.Id("string"))},
+				ParameterNames:        []string{"ctx", "myArg"},
+				ParametersNameAndType: []jen.Code{jen.Id("ctx").Add(jen.Qual("context", "Context")), jen.Id("myArg").Add(jen.Id("string"))},
 				ReturnTypes:           []jen.Code{jen.Id("error")},
 			},
 		},
@@ -132,9 +132,9 @@ func TestNewMethod(t *testing.T) {
 				Name:           "Fn",
 				HasContext:     false,
 				ReturnsError:   false,
-				ParameterNames: []string{"arg0"},
+				ParameterNames: []string{"myArg"},
 				ParametersNameAndType: []jen.Code{
-					jen.Id("arg0").Add(jen.Func().Params().Parens(jen.List(jen.Id("string"), jen.Id("error")))),
+					jen.Id("myArg").Add(jen.Func().Params().Parens(jen.List(jen.Id("string"), jen.Id("error")))),
 				},
 				ReturnTypes: []jen.Code{},
 			},
@@ -155,8 +155,8 @@ func TestNewMethod(t *testing.T) {
 				Name:                  "Fn",
 				HasContext:            true,
 				ReturnsError:          true,
-				ParameterNames:        []string{"ctx", "arg1"},
-				ParametersNameAndType: []jen.Code{jen.Id("ctx").Add(jen.Qual("context", "Context")), jen.Id("arg1").Add(jen.Id("string"))},
+				ParameterNames:        []string{"ctx", "myArg"},
+				ParametersNameAndType: []jen.Code{jen.Id("ctx").Add(jen.Qual("context", "Context")), jen.Id("myArg").Add(jen.Id("string"))},
 				ReturnTypes:           []jen.Code{jen.Id("string"), jen.Id("error")},
 			},
 		},
@@ -172,8 +172,8 @@ func TestNewMethod(t *testing.T) {
 			want: &method.Method{
 				Name:                  "Fn",
 				HasContext:            false,
-				ParameterNames:        []string{"arg0"},
-				ParametersNameAndType: []jen.Code{jen.Id("arg0").Add(jen.Id("interface{}"))},
+				ParameterNames:        []string{"arg"},
+				ParametersNameAndType: []jen.Code{jen.Id("arg").Add(jen.Id("interface{}"))},
 				ReturnTypes:           []jen.Code{jen.Id("interface{}")},
 			},
 		},
@@ -189,8 +189,8 @@ func TestNewMethod(t *testing.T) {
 			want: &method.Method{
 				Name:                  "Fn",
 				HasContext:            false,
-				ParameterNames:        []string{"arg0"},
-				ParametersNameAndType: []jen.Code{jen.Id("arg0").Add(jen.Map(jen.Id("string")).Add(jen.Id("interface{}")))},
+				ParameterNames:        []string{"arg"},
+				ParametersNameAndType: []jen.Code{jen.Id("arg").Add(jen.Map(jen.Id("string")).Add(jen.Id("interface{}")))},
 				ReturnTypes:           []jen.Code{jen.Map(jen.Id("string")).Add(jen.Id("int"))},
 			},
 		},
@@ -215,9 +215,9 @@ func TestNewMethod(t *testing.T) {
 			want: &method.Method{
 				Name:           "Fn",
 				HasContext:     false,
-				ParameterNames: []string{"arg0"},
+				ParameterNames: []string{"argFn"},
 				ParametersNameAndType: []jen.Code{
-					jen.Id("arg0").Add(jen.Func().Params(jen.String()).Add(jen.Bool()))},
+					jen.Id("argFn").Add(jen.Func().Params(jen.String()).Add(jen.Bool()))},
 				ReturnTypes: []jen.Code{jen.Map(jen.Id("string")).Add(jen.Id("int"))},
 			},
 		},
@@ -244,3 +244,315 @@ func TestNewMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMethod_ParametersNameAndSliceType(t *testing.T) {
+	signature := types.NewSignature(nil, types.NewTuple(
+		types.NewVar(token.NoPos, nil, "ctx", rtypes.ContextType),
+		types.NewVar(token.NoPos, nil, "name", types.Typ[types.String]),
+		types.NewVar(token.NoPos, nil, "fields", types.NewSlice(types.Typ[types.String])),
+	), types.NewTuple(), true)
+	m, err := method.ParseMethod("Fn", signature)
+	require.NoError(t, err)
+	require.Equal(t, "func Fn(ctx context.Context, name string, fields ...string)", jen.Func().Id("Fn").Params(m.ParametersNameAndType...).GoString())
+	require.Equal(t, "func Fn(ctx context.Context, name string, fields []string)", jen.Func().Id("Fn").Params(m.ParametersNameAndSliceType...).GoString())
+}
+
+func TestParseMethod_HasCallOptions(t *testing.T) {
+	grpcPkg := types.NewPackage("google.golang.org/grpc", "grpc")
+	callOption := types.NewNamed(types.NewTypeName(token.NoPos, grpcPkg, "CallOption", nil), types.NewInterfaceType(nil, nil), nil)
+	otherPkg := types.NewPackage("github.com/csueiras/rpc", "rpc")
+	otherOption := types.NewNamed(types.NewTypeName(token.NoPos, otherPkg, "CallOption", nil), types.NewInterfaceType(nil, nil), nil)
+
+	tests := []struct {
+		name     string
+		params   []*types.Var
+		variadic bool
+		want     bool
+	}{
+		{
+			name:     "gRPC Call Options",
+			params:   []*types.Var{types.NewVar(token.NoPos, nil, "opts", types.NewSlice(callOption))},
+			variadic: true,
+			want:     true,
+		},
+		{
+			name:     "gRPC Call Options Slice",
+			params:   []*types.Var{types.NewVar(token.NoPos, nil, "opts", types.NewSlice(callOption))},
+			variadic: false,
+			want:     false,
+		},
+		{
+			name:     "Other Call Options",
+			params:   []*types.Var{types.NewVar(token.NoPos, nil, "opts", types.NewSlice(otherOption))},
+			variadic: true,
+			want:     false,
+		},
+		{
+			name:     "Not Variadic",
+			params:   []*types.Var{types.NewVar(token.NoPos, nil, "opt", callOption)},
+			variadic: false,
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := append([]*types.Var{types.NewVar(token.NoPos, nil, "ctx", rtypes.ContextType)}, tt.params...)
+			signature := types.NewSignature(nil, types.NewTuple(params...), types.NewTuple(types.NewVar(token.NoPos, nil, "", rtypes.ErrType)), tt.variadic)
+			m, err := method.ParseMethod("Fn", signature)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, m.HasCallOptions)
+		})
+	}
+}
+
+func TestParseMethod_TypeKinds(t *testing.T) {
+	pkg := types.NewPackage("github.com/csueiras/users", "users")
+	user := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "User", nil), types.NewStruct(nil, nil), nil)
+	userID := types.NewAlias(types.NewTypeName(token.NoPos, pkg, "UserID", nil), types.Typ[types.String])
+	unexportedAlias := types.NewAlias(types.NewTypeName(token.NoPos, pkg, "userIDs", nil), types.NewSlice(types.Typ[types.String]))
+
+	tp := types.NewTypeParam(types.NewTypeName(token.NoPos, pkg, "T", nil), types.Universe.Lookup("any").Type())
+	page := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Page", nil), types.NewStruct(nil, nil), nil)
+	page.SetTypeParams([]*types.TypeParam{tp})
+	userPage, err := types.Instantiate(nil, page, []types.Type{user}, true)
+	require.NoError(t, err)
+
+	closeSignature := types.NewSignature(nil, types.NewTuple(), types.NewTuple(types.NewVar(token.NoPos, nil, "", rtypes.ErrType)), false)
+	ioReader := types.NewNamed(types.NewTypeName(token.NoPos, types.NewPackage("io", "io"), "Reader", nil), types.NewInterfaceType(nil, nil), nil)
+
+	newVar := func(name string, typ types.Type) *types.Var {
+		return types.NewVar(token.NoPos, nil, name, typ)
+	}
+
+	tests := []struct {
+		name string
+		typ  types.Type
+		want string
+	}{
+		{name: "Basic", typ: types.Typ[types.Uint64], want: "uint64"},
+		{name: "Basic: byte", typ: types.Universe.Lookup("byte").Type(), want: "byte"},
+		{name: "Basic: unsafe.Pointer", typ: types.Typ[types.UnsafePointer], want: "unsafe.Pointer"},
+		{name: "Array", typ: types.NewArray(types.Typ[types.Byte], 16), want: "[16]uint8"},
+		{name: "Slice", typ: types.NewSlice(types.NewPointer(user)), want: "[]*users.User"},
+		{name: "Map", typ: types.NewMap(types.Typ[types.String], types.NewSlice(types.Typ[types.Int])), want: "map[string][]int"},
+		{name: "Chan", typ: types.NewChan(types.SendRecv, types.Typ[types.Int]), want: "chan int"},
+		{name: "Chan: receive only", typ: types.NewChan(types.RecvOnly, types.Typ[types.Int]), want: "<-chan int"},
+		{name: "Chan: send only", typ: types.NewChan(types.SendOnly, types.Typ[types.Int]), want: "chan<- int"},
+		{name: "Chan: of receive only chan", typ: types.NewChan(types.SendRecv, types.NewChan(types.RecvOnly, types.Typ[types.Int])), want: "chan (<-chan int)"},
+		{name: "Pointer", typ: types.NewPointer(types.Typ[types.Int]), want: "*int"},
+		{name: "Named", typ: user, want: "users.User"},
+		{name: "Named: predeclared", typ: rtypes.ErrType, want: "error"},
+		{name: "Named: instantiated", typ: userPage, want: "users.Page[users.User]"},
+		{name: "Alias", typ: userID, want: "users.UserID"},
+		{name: "Alias: unexported", typ: unexportedAlias, want: "[]string"},
+		{name: "Alias: predeclared", typ: types.Universe.Lookup("any").Type(), want: "any"},
+		{name: "Interface: empty", typ: types.NewInterfaceType(nil, nil), want: "interface{}"},
+		{
+			name: "Interface: methods and embedded",
+			typ: types.NewInterfaceType(
+				[]*types.Func{types.NewFunc(token.NoPos, nil, "Close", closeSignature)},
+				[]types.Type{ioReader},
+			),
+			want: "interface {\n\tio.Reader\n\tClose() error\n}",
+		},
+		{
+			name: "Struct",
+			typ: types.NewStruct([]*types.Var{
+				types.NewField(token.NoPos, nil, "User", user, true),
+				types.NewField(token.NoPos, nil, "Name", types.Typ[types.String], false),
+				types.NewField(token.NoPos, nil, "Age", types.Typ[types.Int], false),
+			}, []string{"", `json:"name" yaml:"full_name"`, ""}),
+			want: "struct {\n\tusers.User\n\tName string \"json:\\\"name\\\" yaml:\\\"full_name\\\"\"\n\tAge  int\n}",
+		},
+		{
+			name: "Struct: tag as written",
+			typ: types.NewStruct([]*types.Var{
+				types.NewField(token.NoPos, nil, "Name", types.Typ[types.String], false),
+				types.NewField(token.NoPos, nil, "Age", types.Typ[types.Int], false),
+			}, []string{`yaml:"a" json:"a"`, "unconventional"}),
+			want: "struct {\n\tName string \"yaml:\\\"a\\\" json:\\\"a\\\"\"\n\tAge  int    \"unconventional\"\n}",
+		},
+		{
+			name: "Struct: tag with backquote",
+			typ: types.NewStruct([]*types.Var{
+				types.NewField(token.NoPos, nil, "Name", types.Typ[types.String], false),
+			}, []string{"doc:\"`name`\""}),
+			want: "struct {\n\tName string \"doc:\\\"`name`\\\"\"\n}",
+		},
+		{name: "Struct: empty", typ: types.NewStruct(nil, nil), want: "struct{}"},
+		{
+			name: "Signature",
+			typ: types.NewSignature(nil,
+				types.NewTuple(newVar("format", types.Typ[types.String]), newVar("args", types.NewSlice(types.NewInterfaceType(nil, nil)))),
+				types.NewTuple(newVar("", types.Typ[types.Int]), newVar("", rtypes.ErrType)),
+				true,
+			),
+			want: "func(string, ...interface{}) (int, error)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := method.ParseMethod("Fn", types.NewSignature(nil,
+				types.NewTuple(newVar("arg", tt.typ)),
+				types.NewTuple(newVar("", tt.typ)),
+				false,
+			))
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("func Fn(arg %s) %s", tt.want, tt.want), jen.Func().Id("Fn").Params(m.ParametersNameAndType...).Add(m.ReturnTypes...).GoString())
+		})
+	}
+}
+
+func TestParseMethod_UnexportedMembers(t *testing.T) {
+	pkg := types.NewPackage("github.com/csueiras/users", "users")
+	tests := []struct {
+		name    string
+		typ     types.Type
+		wantErr string
+	}{
+		{
+			name: "Struct With Unexported Field",
+			typ: types.NewStruct([]*types.Var{
+				types.NewField(token.NoPos, pkg, "Name", types.Typ[types.String], false),
+				types.NewField(token.NoPos, pkg, "age", types.Typ[types.Int], false),
+			}, nil),
+			wantErr: "struct with unexported field age can't be referenced outside of its package",
+		},
+		{
+			name: "Interface With Unexported Method",
+			typ: types.NewInterfaceType([]*types.Func{
+				types.NewFunc(token.NoPos, pkg, "close", types.NewSignature(nil, types.NewTuple(), types.NewTuple(), false)),
+			}, nil).Complete(),
+			wantErr: "interface with unexported method close can't be referenced outside of its package",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := method.ParseMethod("Fn", types.NewSignature(nil,
+				types.NewTuple(types.NewVar(token.NoPos, nil, "arg", tt.typ)),
+				types.NewTuple(),
+				false,
+			))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseMethod_Names(t *testing.T) {
+	newVar := func(name string, typ types.Type) *types.Var {
+		return types.NewVar(token.NoPos, nil, name, typ)
+	}
+	pkg := types.NewPackage("github.com/csueiras/users", "users")
+	user := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "User", nil), types.NewStruct(nil, nil), nil)
+
+	t.Run("Named results", func(t *testing.T) {
+		m, err := method.ParseMethod("GetUser", types.NewSignature(nil,
+			types.NewTuple(newVar("c", rtypes.ContextType), newVar("id", types.Typ[types.String])),
+			types.NewTuple(newVar("user", types.NewPointer(user)), newVar("err", rtypes.ErrType)),
+			false,
+		))
+		require.NoError(t, err)
+		require.Equal(t, []string{"ctx", "id"}, m.ParameterNames)
+		require.Equal(t, []string{"user", "res1"}, m.ResultNames)
+		require.Equal(t, []jen.Code{
+			jen.Id("user").Add(jen.Op("*").Add(jen.Qual("github.com/csueiras/users", "User"))),
+			jen.Id("res1").Add(jen.Id("error")),
+		}, m.ResultsNameAndType)
+		require.Equal(t, []jen.Code{jen.Op("*").Add(jen.Qual("github.com/csueiras/users", "User")), jen.Id("error")}, m.ReturnTypes)
+	})
+
+	t.Run("Unnamed results", func(t *testing.T) {
+		m, err := method.ParseMethod("GetUser", types.NewSignature(nil,
+			types.NewTuple(newVar("", types.Typ[types.String])),
+			types.NewTuple(newVar("", types.NewPointer(user)), newVar("", rtypes.ErrType)),
+			false,
+		))
+		require.NoError(t, err)
+		require.Equal(t, []string{"arg0"}, m.ParameterNames)
+		require.Nil(t, m.ResultNames)
+		require.Equal(t, m.ReturnTypes, m.ResultsNameAndType)
+	})
+
+	t.Run("Collisions", func(t *testing.T) {
+		m, err := method.ParseMethod("Fn", types.NewSignature(nil,
+			types.NewTuple(
+				newVar("ctx", types.Typ[types.String]),
+				newVar("err", rtypes.ErrType),
+				newVar("nonRetryableErr", types.Typ[types.Bool]),
+				newVar("users", types.NewPointer(user)),
+				newVar("r0", types.Typ[types.Int]),
+				newVar("_", types.Typ[types.Int]),
+				newVar("string", types.Typ[types.String]),
+				newVar("arg0", types.Typ[types.Int]),
+			),
+			types.NewTuple(newVar("", rtypes.ErrType)),
+			false,
+		))
+		require.NoError(t, err)
+		require.Equal(t, []string{"arg0_1", "arg1", "arg2", "arg3", "arg4", "arg5", "arg6", "arg0"}, m.ParameterNames)
+	})
+
+	t.Run("Reserved names", func(t *testing.T) {
+		m, err := method.ParseMethod("Fn", types.NewSignature(nil,
+			types.NewTuple(newVar("s", types.Typ[types.String]), newVar("name", types.Typ[types.String])),
+			types.NewTuple(newVar("", rtypes.ErrType)),
+			false,
+		))
+		require.NoError(t, err)
+		require.Equal(t, []string{"s", "name"}, m.ParameterNames)
+
+		reserved, err := m.WithReservedNames("s")
+		require.NoError(t, err)
+		require.Equal(t, []string{"arg0", "name"}, reserved.ParameterNames)
+		require.Equal(t, []jen.Code{jen.Id("arg0").Add(jen.Id("string")), jen.Id("name").Add(jen.Id("string"))}, reserved.ParametersNameAndType)
+
+		// The original method is left untouched
+		require.Equal(t, []string{"s", "name"}, m.ParameterNames)
+	})
+}
+
+func TestParseTypeParams(t *testing.T) {
+	newTypeParam := func(name string, constraint types.Type) *types.TypeParam {
+		return types.NewTypeParam(types.NewTypeName(token.NoPos, nil, name, nil), constraint)
+	}
+	numberConstraint := types.NewInterfaceType(nil, []types.Type{
+		types.NewUnion([]*types.Term{
+			types.NewTerm(true, types.Typ[types.Int]),
+			types.NewTerm(false, types.Typ[types.Float64]),
+		}),
+	})
+	numberConstraint.MarkImplicit()
+
+	k := newTypeParam("K", types.Universe.Lookup("comparable").Type())
+	v := newTypeParam("V", numberConstraint)
+	named := types.NewNamed(types.NewTypeName(token.NoPos, nil, "Cache", nil), types.NewStruct(nil, nil), nil)
+	named.SetTypeParams([]*types.TypeParam{k, v})
+
+	params, err := method.ParseTypeParams(named.TypeParams())
+	require.NoError(t, err)
+	require.Equal(t, 2, len(params))
+	require.Equal(t, "K", params[0].Name)
+	require.Equal(t, "V", params[1].Name)
+	require.Equal(t, "type Cache[K comparable, V ~int | float64] struct{}", jen.Type().Id("Cache").Add(method.TypeParamsDecl(params)).Struct().GoString())
+	require.Equal(t, "Cache[K, V]", jen.Id("Cache").Add(method.TypeParamsRef(params)).GoString())
+
+	m, err := method.ParseMethod("Get", types.NewSignature(nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "key", k)),
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", v), types.NewVar(token.NoPos, nil, "", rtypes.ErrType)),
+		false,
+	))
+	require.NoError(t, err)
+	require.Equal(t, []jen.Code{jen.Id("key").Add(jen.Id("K"))}, m.ParametersNameAndType)
+	require.Equal(t, []jen.Code{jen.Id("V"), jen.Id("error")}, m.ReturnTypes)
+
+	t.Run("Non-generic", func(t *testing.T) {
+		params, err := method.ParseTypeParams(nil)
+		require.NoError(t, err)
+		require.Empty(t, params)
+		require.Equal(t, "type Cache struct{}", jen.Type().Id("Cache").Add(method.TypeParamsDecl(params)).Struct().GoString())
+		require.Equal(t, "Cache", jen.Id("Cache").Add(method.TypeParamsRef(params)).GoString())
+	})
+}
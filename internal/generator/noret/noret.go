@@ -5,18 +5,21 @@ import (
 	"github.com/dave/jennifer/jen"
 )
 
-// NoReturn is a code generator that injects the middleware to delegates that don't return anything
+// NoReturn is a code generator that injects the middleware to delegates that don't return anything, the errors from the
+// middleware are given to the no-return error handler as there is no way to surface them to the caller
 type NoReturn struct {
 	method       *method.Method
 	structName   string
+	typeParams   []*method.TypeParam
 	receiverName string
 }
 
 // NewNoReturn is a ctor for NoReturn
-func NewNoReturn(method *method.Method, structName string, receiverName string) *NoReturn {
+func NewNoReturn(method *method.Method, structName string, typeParams []*method.TypeParam, receiverName string) *NoReturn {
 	return &NoReturn{
 		method:       method,
 		structName:   structName,
+		typeParams:   typeParams,
 		receiverName: receiverName,
 	}
 }
@@ -35,10 +38,13 @@ func (p *NoReturn) Statement() (*jen.Statement, error) {
 		jen.Return(jen.Nil()),
 	)
 
-	return jen.Func().Params(jen.Id(p.receiverName).Op("*").Id(p.structName)).Id(p.method.Name).Call(methodArgParams...).Block(
-		jen.Id("err").Op(":=").Id(p.receiverName).Dot("run").Call(ctxParam, p.method.ConstantRef(p.structName), call),
+	return jen.Func().Params(jen.Id(p.receiverName).Op("*").Id(p.structName).Add(method.TypeParamsRef(p.typeParams))).Id(p.method.Name).Call(methodArgParams...).Block(
+		jen.Id("err").Op(":=").Add(p.method.RunnerRef(p.receiverName)).Dot("Run").Call(ctxParam, call),
+		// if err != nil {
+		//   r.noReturnErrorHandler(methodName, err)
+		// }
 		jen.If(jen.Id("err").Op("!=").Nil()).Block(
-			jen.Panic(jen.Id("err")),
+			jen.Id(p.receiverName).Dot("noReturnErrorHandler").Call(p.method.ConstantRef(p.structName), jen.Id("err")),
 		),
 	), nil
 }
@@ -26,30 +26,30 @@ func TestNoReturn_Statement(t *testing.T) {
 			methodName: "MyFunction",
 			signature:  types.NewSignature(nil, types.NewTuple(), types.NewTuple(), false),
 			want: `func (r *Resilient) MyFunction() {
-	err := r.run(context.Background(), ResilientMethods.MyFunction, func(_ context.Context) error {
+	err := r.runners.MyFunction.Run(context.Background(), func(_ context.Context) error {
 		r.delegate.MyFunction()
 		return nil
 	})
 	if err != nil {
-		panic(err)
+		r.noReturnErrorHandler(ResilientMethods.MyFunction, err)
 	}
 }`,
 			wantErr: false,
 		},
 		{
-			name:       "MyFunction(ctx context.Context, arg1 string)",
+			name:       "MyFunction(ctx context.Context, myArg string)",
 			methodName: "MyFunction",
 			signature: types.NewSignature(nil, types.NewTuple(
 				ctxVar,
 				types.NewVar(token.NoPos, nil, "myArg", types.Typ[types.String]),
 			), types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String])), false),
-			want: `func (r *Resilient) MyFunction(ctx context.Context, arg1 string) {
-	err := r.run(ctx, ResilientMethods.MyFunction, func(ctx context.Context) error {
-		r.delegate.MyFunction(ctx, arg1)
+			want: `func (r *Resilient) MyFunction(ctx context.Context, myArg string) {
+	err := r.runners.MyFunction.Run(ctx, func(ctx context.Context) error {
+		r.delegate.MyFunction(ctx, myArg)
 		return nil
 	})
 	if err != nil {
-		panic(err)
+		r.noReturnErrorHandler(ResilientMethods.MyFunction, err)
 	}
 }`,
 			wantErr: false,
@@ -60,7 +60,7 @@ func TestNoReturn_Statement(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			m, err := method.ParseMethod(tt.methodName, tt.signature)
 			require.NoError(t, err)
-			ret := noret.NewNoReturn(m, "Resilient", "r")
+			ret := noret.NewNoReturn(m, "Resilient", nil, "r")
 			buf := &bytes.Buffer{}
 			s, err := ret.Statement()
 			if tt.wantErr {
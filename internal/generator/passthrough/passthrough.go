@@ -9,14 +9,16 @@ import (
 type PassThrough struct {
 	method       *method.Method
 	structName   string
+	typeParams   []*method.TypeParam
 	receiverName string
 }
 
 // NewPassThrough is a ctor for PassThrough
-func NewPassThrough(method *method.Method, structName string, receiverName string) *PassThrough {
+func NewPassThrough(method *method.Method, structName string, typeParams []*method.TypeParam, receiverName string) *PassThrough {
 	return &PassThrough{
 		method:       method,
 		structName:   structName,
+		typeParams:   typeParams,
 		receiverName: receiverName,
 	}
 }
@@ -34,7 +36,7 @@ func (p *PassThrough) Statement() (*jen.Statement, error) {
 		block = append(block, delegateCall)
 	}
 
-	return jen.Func().Params(jen.Id(p.receiverName).Op("*").Id(p.structName)).Id(p.method.Name).Call(methodArgParams...).Block(
+	return jen.Func().Params(jen.Id(p.receiverName).Op("*").Id(p.structName).Add(method.TypeParamsRef(p.typeParams))).Id(p.method.Name).Call(methodArgParams...).Block(
 		block...,
 	), nil
 }
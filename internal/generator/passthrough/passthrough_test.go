@@ -28,8 +28,8 @@ func TestPassThrough_Statement(t *testing.T) {
 				ctxVar,
 				types.NewVar(token.NoPos, nil, "myArg", types.Typ[types.String]),
 			), types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String])), false),
-			want: `func (r *resilient) MyFunction(ctx context.Context, arg1 string) {
-	return r.delegate.MyFunction(ctx, arg1)
+			want: `func (r *resilient) MyFunction(ctx context.Context, myArg string) {
+	return r.delegate.MyFunction(ctx, myArg)
 }`,
 			wantErr: false,
 		},
@@ -40,8 +40,8 @@ func TestPassThrough_Statement(t *testing.T) {
 				ctxVar,
 				types.NewVar(token.NoPos, nil, "myArg", types.Typ[types.String]),
 			), types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String])), false),
-			want: `func (r *resilient) MyFunction(ctx context.Context, arg1 string) {
-	return r.delegate.MyFunction(ctx, arg1)
+			want: `func (r *resilient) MyFunction(ctx context.Context, myArg string) {
+	return r.delegate.MyFunction(ctx, myArg)
 }`,
 			wantErr: false,
 		},
@@ -60,7 +60,7 @@ func TestPassThrough_Statement(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			m, err := method.ParseMethod(tt.methodName, tt.signature)
 			require.NoError(t, err)
-			ret := passthrough.NewPassThrough(m, "resilient", "r")
+			ret := passthrough.NewPassThrough(m, "resilient", nil, "r")
 			buf := &bytes.Buffer{}
 			s, err := ret.Statement()
 			if tt.wantErr {
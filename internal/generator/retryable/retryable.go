@@ -9,17 +9,28 @@ import (
 const (
 	errVarName             = "err"
 	nonRetryableErrVarName = "nonRetryableErr"
+	predicateVarName       = "p"
+	fallbackVarName        = "fallback"
+	resultsTypeName        = "results"
+	attemptsVarName        = "attempts"
+	attemptVarName         = "attempt"
+	latestVarName          = "latest"
 )
 
-// Retryable is a code generator for a method that can be retried on error
+// Retryable is a code generator for a method that can be retried on error. The values returned by the delegate are only
+// committed as the method's results when the attempt is final, that is when it succeeded or failed with an error that
+// must not be retried, this guarantees that values from previous attempts never leak to the caller. As a middleware (e.g. a
+// timeout) may give up on an attempt that keeps running, the attempts are numbered and the values of an attempt are never
+// committed over those of a newer attempt, the caller picks up the committed values once the runner is done.
 type Retryable struct {
 	method       *method.Method
 	structName   string
+	typeParams   []*method.TypeParam
 	receiverName string
 }
 
 // NewRetryable is a constructor for Retryable, the given method must be an error-returning method
-func NewRetryable(method *method.Method, structName string, receiverName string) *Retryable {
+func NewRetryable(method *method.Method, structName string, typeParams []*method.TypeParam, receiverName string) *Retryable {
 	if !method.ReturnsError {
 		panic("method does not return an error and is thus not retryable")
 	}
@@ -27,6 +38,7 @@ func NewRetryable(method *method.Method, structName string, receiverName string)
 	return &Retryable{
 		method:       method,
 		structName:   structName,
+		typeParams:   typeParams,
 		receiverName: receiverName,
 	}
 }
@@ -37,73 +49,235 @@ func (r *Retryable) Statement() (*jen.Statement, error) {
 	if err != nil {
 		return nil, err
 	}
-	return jen.Func().Params(jen.Id(r.receiverName).Op("*").Id(r.structName)).Id(r.method.Name).Call(r.method.ParametersNameAndType...).Params(r.method.ReturnTypes...).Block(
+	return jen.Func().Params(jen.Id(r.receiverName).Op("*").Id(r.structName).Add(method.TypeParamsRef(r.typeParams))).Id(r.method.Name).Call(r.method.ParametersNameAndType...).Params(r.method.ResultsNameAndType...).Block(
 		methodCallStatements...,
 	), nil
 }
 
+// ResultPredicateOption generates the Option that configures the predicate that inspects the values returned by this
+// method to determine whether the attempt should be retried, nil is returned for methods that only return an error
+func (r *Retryable) ResultPredicateOption() (*jen.Statement, error) {
+	if !r.hasResultPredicate() {
+		return nil, nil
+	}
+	optionName := fmt.Sprintf("With%s%sResultPredicate", r.structName, r.method.Name)
+	return jen.Func().Id(optionName).Add(method.TypeParamsDecl(r.typeParams)).Params(jen.Id("fn").Add(r.resultPredicateType())).Id("Option").Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("base")).Block(
+			jen.If(jen.Id("o").Dot("resultPredicates").Op("==").Nil()).Block(
+				jen.Id("o").Dot("resultPredicates").Op("=").Make(jen.Map(jen.Id("string")).Interface()),
+			),
+			jen.Id("o").Dot("resultPredicates").Index(r.method.ConstantRef(r.structName)).Op("=").Id("fn"),
+		)),
+	), nil
+}
+
+// FallbackOption generates the Option that configures the fallback for this method, the fallback is given the method's
+// arguments along with the error of the failed call and provides the values returned in its stead
+func (r *Retryable) FallbackOption() (*jen.Statement, error) {
+	optionName := fmt.Sprintf("With%s%sFallback", r.structName, r.method.Name)
+	return jen.Func().Id(optionName).Add(method.TypeParamsDecl(r.typeParams)).Params(jen.Id("fn").Add(r.fallbackType())).Id("Option").Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("base")).Block(
+			jen.If(jen.Id("o").Dot("fallbacks").Op("==").Nil()).Block(
+				jen.Id("o").Dot("fallbacks").Op("=").Make(jen.Map(jen.Id("string")).Interface()),
+			),
+			jen.Id("o").Dot("fallbacks").Index(r.method.ConstantRef(r.structName)).Op("=").Id("fn"),
+		)),
+	), nil
+}
+
+// fallbackType is the type of the fallback that receives the method's arguments followed by the error of the failed call
+func (r *Retryable) fallbackType() *jen.Statement {
+	params := append(append([]jen.Code{}, r.method.ParametersNameAndSliceType...), jen.Id(errVarName).Error())
+	return jen.Func().Params(params...).Params(r.method.ReturnTypes...)
+}
+
+// FallbackFromDelegate generates the fallback for this method that serves the call from the given delegate
+func (r *Retryable) FallbackFromDelegate(delegate jen.Code) *jen.Statement {
+	params := append(append([]jen.Code{}, r.method.ParametersNameAndSliceType...), jen.Id("_").Error())
+	return jen.Func().Params(params...).Params(r.method.ReturnTypes...).Block(
+		jen.Return(jen.Add(delegate).Dot(r.method.Name).Call(r.method.Parameters()...)),
+	)
+}
+
+// fallbackCall generates the code that serves the failed call from the fallback configured for the method, if any
+func (r *Retryable) fallbackCall() jen.Code {
+	args := make([]jen.Code, 0, len(r.method.ParameterNames)+1)
+	for _, name := range r.method.ParameterNames {
+		args = append(args, jen.Id(name))
+	}
+	args = append(args, jen.Id(errVarName))
+
+	// if fallback, _ := r.fallbacks[methodName].(func(args..., err error) (T0, T1, ..., error)); fallback != nil {
+	//   return fallback(args..., err)
+	// }
+	return jen.If(
+		jen.List(jen.Id(fallbackVarName), jen.Id("_")).Op(":=").Id(r.receiverName).Dot("fallbacks").Index(r.method.ConstantRef(r.structName)).Assert(r.fallbackType()),
+		jen.Id(fallbackVarName).Op("!=").Nil(),
+	).Block(
+		jen.Return(jen.Id(fallbackVarName).Call(args...)),
+	)
+}
+
+// hasResultPredicate is true when the method returns values other than the error that can be inspected by a predicate
+func (r *Retryable) hasResultPredicate() bool {
+	return len(r.method.ReturnTypes) > 1
+}
+
+// resultPredicateType is the type of the predicate that receives all the values returned by the method
+func (r *Retryable) resultPredicateType() *jen.Statement {
+	return jen.Func().Params(r.method.ReturnTypes...).Bool()
+}
+
 func (r *Retryable) methodCall() ([]jen.Code, error) {
 	params := r.method.Parameters()
+	if r.method.HasCallOptions {
+		// Each attempt is given its own copy of the gRPC call options, the options appended by the interceptors of an
+		// attempt could otherwise reuse the spare capacity of the caller's slice and leak into the next attempt
+		last := len(params) - 1
+		opts := r.method.ParameterNames[last]
+		params[last] = jen.Append(jen.Index().Qual("google.golang.org/grpc", "CallOption").Call(jen.Nil()), jen.Id(opts).Op("...")).Op("...")
+	}
 
 	statements := []jen.Code{
 		jen.Var().Id(nonRetryableErrVarName).Id("error"),
 	}
 
 	// Declare the return vars
-	returnVars := make([]jen.Code, 0, len(r.method.ReturnTypes))
+	attemptVars := make([]jen.Code, 0, len(r.method.ReturnTypes))
+	resultVars := make([]jen.Code, 0, len(r.method.ReturnTypes))
+	resultFields := []jen.Code{jen.Id(attemptVarName).Int64()}
+	committedValues := []jen.Code{jen.Id(attemptVarName)}
+	committedFields := make([]jen.Code, 0, len(r.method.ReturnTypes))
+	zeroValues := make([]jen.Code, 0, len(r.method.ReturnTypes))
+	latest := jen.Id(latestVarName)
 
 	for i := 0; i < len(r.method.ReturnTypes); i++ {
-		// Use auto-generated names for variables to avoid conflicts with existing names within the signature
-		varName := fmt.Sprintf("r%d", i)
 		if *r.method.ReturnErrorIndex == i {
-			returnVars = append(returnVars, jen.Id("err"))
+			attemptVars = append(attemptVars, jen.Id(errVarName))
+			resultVars = append(resultVars, jen.Id(nonRetryableErrVarName))
+			resultFields = append(resultFields, jen.Id(errVarName).Error())
+			committedValues = append(committedValues, jen.Id(errVarName))
+			committedFields = append(committedFields, jen.Add(latest).Dot(errVarName))
+			zeroValues = append(zeroValues, jen.Id(errVarName))
 
 			// Don't declare the error variable
 			continue
 		}
 
-		// Build the list of values to return from the execution of the method
-		returnVars = append(returnVars, jen.Id(varName))
+		// Use auto-generated names for variables to avoid conflicts with existing names within the signature, the
+		// values returned by each attempt are held in aN and only those of the final attempt are committed into rN
+		varName := fmt.Sprintf("r%d", i)
+		attemptVar := fmt.Sprintf("a%d", i)
+		attemptVars = append(attemptVars, jen.Id(attemptVar))
+		resultVars = append(resultVars, jen.Id(varName))
+		resultFields = append(resultFields, jen.Id(varName).Add(r.method.ReturnTypes[i]))
+		committedValues = append(committedValues, jen.Id(attemptVar))
+		committedFields = append(committedFields, jen.Add(latest).Dot(varName))
+		zeroValues = append(zeroValues, jen.Op("*").New(r.method.ReturnTypes[i]))
 
 		// Declare var for the values to be returned
 		statements = append(statements, jen.Var().Id(varName).Add(r.method.ReturnTypes[i]))
 	}
 
+	// type results struct {
+	//   attempt int64
+	//   r0      T0
+	//   ...
+	//   err     error
+	// }
+	// var attempts struct {
+	//   started   atomic.Int64
+	//   committed atomic.Pointer[results]
+	// }
+	statements = append(statements,
+		jen.Type().Id(resultsTypeName).Struct(resultFields...),
+		// The counter and the committed values share a single allocation as both are captured by the attempts
+		jen.Var().Id(attemptsVarName).Struct(
+			jen.Id("started").Qual("sync/atomic", "Int64"),
+			jen.Id("committed").Qual("sync/atomic", "Pointer").Types(jen.Id(resultsTypeName)),
+		),
+	)
+
 	ctxParamName, ctxParam := r.method.ContextParam()
+	committed := jen.Id(attemptsVarName).Dot("committed")
 
 	// anonymous function passed to the middleware
 	call := jen.Func().Call(jen.Id(ctxParamName).Qual("context", "Context")).Params(jen.Id("error")).Block(
-		// var err error
-		jen.Var().Id("err").Id("error"),
-		// r0, r1, ..., err = r.delegate.Fn(args...)
-		jen.List(returnVars...).Op("=").Id(r.receiverName).Dot("delegate").Dot(r.method.Name).Call(params...),
-		// if r.errorPredicate(methodName, err) {
+		// attempt := attempts.started.Add(1)
+		jen.Id(attemptVarName).Op(":=").Id(attemptsVarName).Dot("started").Dot("Add").Call(jen.Lit(1)),
+		// a0, a1, ..., err := r.delegate.Fn(args...)
+		jen.List(attemptVars...).Op(":=").Id(r.receiverName).Dot("delegate").Dot(r.method.Name).Call(params...),
+		r.resultPredicateCheck(attemptVars),
+		// if err != nil && r.shouldRetry(methodName, err) {
 		//  return err
 		// }
-		jen.If(jen.Id(r.receiverName).Dot("errorPredicate").Call(r.method.ConstantRef(r.structName), jen.Id(errVarName))).Block(
-			jen.Return(jen.Id("err")),
+		jen.If(jen.Id(errVarName).Op("!=").Nil().Op("&&").Id(r.receiverName).Dot("shouldRetry").Call(r.method.ConstantRef(r.structName), jen.Id(errVarName))).Block(
+			jen.Return(jen.Id(errVarName)),
+		),
+		// for {
+		//   latest := attempts.committed.Load()
+		//   if latest != nil && latest.attempt > attempt {
+		//     return context.Canceled
+		//   }
+		//   if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, a1, ..., err}) {
+		//     return nil
+		//   }
+		// }
+		jen.For().Block(
+			jen.Add(latest).Op(":=").Add(committed).Dot("Load").Call(),
+			jen.If(jen.Add(latest).Op("!=").Nil().Op("&&").Add(latest).Dot(attemptVarName).Op(">").Id(attemptVarName)).Block(
+				// The attempt was given up on, its error is never seen
+				jen.Return(jen.Qual("context", "Canceled")),
+			),
+			jen.If(jen.Add(committed).Dot("CompareAndSwap").Call(latest, jen.Op("&").Id(resultsTypeName).Values(committedValues...))).Block(
+				jen.Return(jen.Nil()),
+			),
 		),
-		// nonRetryableErr = err
-		jen.Id(nonRetryableErrVarName).Op("=").Id(errVarName),
-		// return nil
-		jen.Return(jen.Nil()),
 	)
 
-	statements = append(statements, jen.Id("err").Op(":=").Id(r.receiverName).Dot("run").Call(ctxParam, r.method.ConstantRef(r.structName), call))
+	statements = append(statements, jen.Id(errVarName).Op(":=").Add(r.method.RunnerRef(r.receiverName)).Dot("Run").Call(ctxParam, call))
 
-	nonRetryErrReturns := make([]jen.Code, len(returnVars))
-	copy(nonRetryErrReturns, returnVars)
-	nonRetryErrReturns[*r.method.ReturnErrorIndex] = jen.Id(nonRetryableErrVarName)
+	// if err != nil {
+	//   if fallback, _ := ...; fallback != nil {...}
+	//   return *new(T0), *new(T1), ..., err
+	// }
+	statements = append(statements, jen.If(jen.Id(errVarName).Op("!=").Nil()).Block(
+		r.fallbackCall(),
+		jen.Return(zeroValues...),
+	))
 
-	// if nonRetryableErr != nil {
-	//   return ....
+	// if latest := attempts.committed.Load(); latest != nil {
+	//   r0, r1, ..., nonRetryableErr = latest.r0, latest.r1, ..., latest.err
 	// }
-	statements = append(statements, jen.If(jen.Id(nonRetryableErrVarName).Op("!=").Nil()).Block(
-		jen.Return(nonRetryErrReturns...),
+	statements = append(statements, jen.If(jen.Add(latest).Op(":=").Add(committed).Dot("Load").Call(), jen.Add(latest).Op("!=").Nil()).Block(
+		jen.List(resultVars...).Op("=").List(committedFields...),
 	))
 
-	// return r0, r1, ..., err
-	statements = append(statements, jen.Return(returnVars...))
+	// return r0, r1, ..., nonRetryableErr
+	statements = append(statements, jen.Return(resultVars...))
 
 	return statements, nil
 }
+
+// resultPredicateCheck generates the code that consults the result predicate configured for the method, if any, a result
+// rejected by the predicate is retried even when the delegate didn't fail
+func (r *Retryable) resultPredicateCheck(attemptVars []jen.Code) jen.Code {
+	if !r.hasResultPredicate() {
+		return jen.Null()
+	}
+	// if p, ok := r.resultPredicates[methodName].(func(T0, T1, ..., error) bool); ok && p(a0, a1, ..., err) {
+	//   if err == nil {
+	//     return ErrRetryableResult
+	//   }
+	//   return err
+	// }
+	return jen.If(
+		jen.List(jen.Id(predicateVarName), jen.Id("ok")).Op(":=").Id(r.receiverName).Dot("resultPredicates").Index(r.method.ConstantRef(r.structName)).Assert(r.resultPredicateType()),
+		jen.Id("ok").Op("&&").Id(predicateVarName).Call(attemptVars...),
+	).Block(
+		jen.If(jen.Id(errVarName).Op("==").Nil()).Block(
+			jen.Return(jen.Id("ErrRetryableResult")),
+		),
+		jen.Return(jen.Id(errVarName)),
+	)
+}
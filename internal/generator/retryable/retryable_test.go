@@ -5,6 +5,7 @@ import (
 	"github.com/csueiras/reinforcer/internal/generator/method"
 	"github.com/csueiras/reinforcer/internal/generator/retryable"
 	rtypes "github.com/csueiras/reinforcer/internal/types"
+	"github.com/dave/jennifer/jen"
 	"github.com/stretchr/testify/require"
 	"go/token"
 	"go/types"
@@ -14,6 +15,8 @@ import (
 func TestRetryable_Statement(t *testing.T) {
 	errVar := types.NewVar(token.NoPos, nil, "", rtypes.ErrType)
 	ctxVar := types.NewVar(token.NoPos, nil, "ctx", rtypes.ContextType)
+	grpcPkg := types.NewPackage("google.golang.org/grpc", "grpc")
+	callOption := types.NewNamed(types.NewTypeName(token.NoPos, grpcPkg, "CallOption", nil), types.NewInterfaceType(nil, nil), nil)
 
 	tests := []struct {
 		name       string
@@ -28,19 +31,40 @@ func TestRetryable_Statement(t *testing.T) {
 			signature:  types.NewSignature(nil, types.NewTuple(), types.NewTuple(errVar), false),
 			want: `func (r *Resilient) MyFunction() error {
 	var nonRetryableErr error
-	err := r.run(context.Background(), ResilientMethods.MyFunction, func(_ context.Context) error {
-		var err error
-		err = r.delegate.MyFunction()
-		if r.errorPredicate(ResilientMethods.MyFunction, err) {
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := r.runners.MyFunction.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := r.delegate.MyFunction()
+		if err != nil && r.shouldRetry(ResilientMethods.MyFunction, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return nonRetryableErr
+	if err != nil {
+		if fallback, _ := r.fallbacks[ResilientMethods.MyFunction].(func(err error) error); fallback != nil {
+			return fallback(err)
+		}
+		return err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
 	}
-	return err
+	return nonRetryableErr
 }`,
 			wantErr: false,
 		},
@@ -51,19 +75,47 @@ func TestRetryable_Statement(t *testing.T) {
 			want: `func (r *Resilient) MyFunction() (string, error) {
 	var nonRetryableErr error
 	var r0 string
-	err := r.run(context.Background(), ResilientMethods.MyFunction, func(_ context.Context) error {
-		var err error
-		r0, err = r.delegate.MyFunction()
-		if r.errorPredicate(ResilientMethods.MyFunction, err) {
+	type results struct {
+		attempt int64
+		r0      string
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := r.runners.MyFunction.Run(context.Background(), func(_ context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := r.delegate.MyFunction()
+		if p, ok := r.resultPredicates[ResilientMethods.MyFunction].(func(string, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		if err != nil && r.shouldRetry(ResilientMethods.MyFunction, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return r0, nonRetryableErr
+	if err != nil {
+		if fallback, _ := r.fallbacks[ResilientMethods.MyFunction].(func(err error) (string, error)); fallback != nil {
+			return fallback(err)
+		}
+		return *new(string), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
 	}
-	return r0, err
+	return r0, nonRetryableErr
 }`,
 			wantErr: false,
 		},
@@ -74,22 +126,96 @@ func TestRetryable_Statement(t *testing.T) {
 				ctxVar,
 				types.NewVar(token.NoPos, nil, "myArg", types.Typ[types.String]),
 			), types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String]), errVar), false),
-			want: `func (r *Resilient) MyFunction(ctx context.Context, arg1 string) (string, error) {
+			want: `func (r *Resilient) MyFunction(ctx context.Context, myArg string) (string, error) {
 	var nonRetryableErr error
 	var r0 string
-	err := r.run(ctx, ResilientMethods.MyFunction, func(ctx context.Context) error {
-		var err error
-		r0, err = r.delegate.MyFunction(ctx, arg1)
-		if r.errorPredicate(ResilientMethods.MyFunction, err) {
+	type results struct {
+		attempt int64
+		r0      string
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := r.runners.MyFunction.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		a0, err := r.delegate.MyFunction(ctx, myArg)
+		if p, ok := r.resultPredicates[ResilientMethods.MyFunction].(func(string, error) bool); ok && p(a0, err) {
+			if err == nil {
+				return ErrRetryableResult
+			}
+			return err
+		}
+		if err != nil && r.shouldRetry(ResilientMethods.MyFunction, err) {
+			return err
+		}
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, a0, err}) {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		if fallback, _ := r.fallbacks[ResilientMethods.MyFunction].(func(ctx context.Context, myArg string, err error) (string, error)); fallback != nil {
+			return fallback(ctx, myArg, err)
+		}
+		return *new(string), err
+	}
+	if latest := attempts.committed.Load(); latest != nil {
+		r0, nonRetryableErr = latest.r0, latest.err
+	}
+	return r0, nonRetryableErr
+}`,
+			wantErr: false,
+		},
+		{
+			name:       "Function with gRPC call options",
+			methodName: "MyFunction",
+			signature: types.NewSignature(nil, types.NewTuple(
+				ctxVar,
+				types.NewVar(token.NoPos, nil, "opts", types.NewSlice(callOption)),
+			), types.NewTuple(errVar), true),
+			want: `func (r *Resilient) MyFunction(ctx context.Context, opts ...grpc.CallOption) error {
+	var nonRetryableErr error
+	type results struct {
+		attempt int64
+		err     error
+	}
+	var attempts struct {
+		started   atomic.Int64
+		committed atomic.Pointer[results]
+	}
+	err := r.runners.MyFunction.Run(ctx, func(ctx context.Context) error {
+		attempt := attempts.started.Add(1)
+		err := r.delegate.MyFunction(ctx, append([]grpc.CallOption(nil), opts...)...)
+		if err != nil && r.shouldRetry(ResilientMethods.MyFunction, err) {
 			return err
 		}
-		nonRetryableErr = err
-		return nil
+		for {
+			latest := attempts.committed.Load()
+			if latest != nil && latest.attempt > attempt {
+				return context.Canceled
+			}
+			if attempts.committed.CompareAndSwap(latest, &results{attempt, err}) {
+				return nil
+			}
+		}
 	})
-	if nonRetryableErr != nil {
-		return r0, nonRetryableErr
+	if err != nil {
+		if fallback, _ := r.fallbacks[ResilientMethods.MyFunction].(func(ctx context.Context, opts []grpc.CallOption, err error) error); fallback != nil {
+			return fallback(ctx, opts, err)
+		}
+		return err
 	}
-	return r0, err
+	if latest := attempts.committed.Load(); latest != nil {
+		nonRetryableErr = latest.err
+	}
+	return nonRetryableErr
 }`,
 			wantErr: false,
 		},
@@ -99,7 +225,7 @@ func TestRetryable_Statement(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			m, err := method.ParseMethod(tt.methodName, tt.signature)
 			require.NoError(t, err)
-			ret := retryable.NewRetryable(m, "Resilient", "r")
+			ret := retryable.NewRetryable(m, "Resilient", nil, "r")
 			buf := &bytes.Buffer{}
 			s, err := ret.Statement()
 			if tt.wantErr {
@@ -120,7 +246,88 @@ func TestRetryable_Statement(t *testing.T) {
 		require.Panics(t, func() {
 			m, err := method.ParseMethod("Fn", types.NewSignature(nil, types.NewTuple(), types.NewTuple(), false))
 			require.NoError(t, err)
-			retryable.NewRetryable(m, "Resilient", "r")
+			retryable.NewRetryable(m, "Resilient", nil, "r")
 		})
 	})
 }
+
+func TestRetryable_FallbackOption(t *testing.T) {
+	errVar := types.NewVar(token.NoPos, nil, "", rtypes.ErrType)
+	ctxVar := types.NewVar(token.NoPos, nil, "ctx", rtypes.ContextType)
+
+	signature := types.NewSignature(nil, types.NewTuple(
+		ctxVar,
+		types.NewVar(token.NoPos, nil, "fields", types.NewSlice(types.Typ[types.String])),
+	), types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String]), errVar), true)
+	m, err := method.ParseMethod("MyFunction", signature)
+	require.NoError(t, err)
+	ret := retryable.NewRetryable(m, "Resilient", nil, "r")
+
+	t.Run("Option", func(t *testing.T) {
+		s, err := ret.FallbackOption()
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+		require.NoError(t, s.Render(buf))
+		require.Equal(t, `func WithResilientMyFunctionFallback(fn func(ctx context.Context, fields []string, err error) (string, error)) Option {
+	return func(o *base) {
+		if o.fallbacks == nil {
+			o.fallbacks = make(map[string]interface{})
+		}
+		o.fallbacks[ResilientMethods.MyFunction] = fn
+	}
+}`, buf.String())
+	})
+
+	t.Run("From Delegate", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, jen.Var().Id("fn").Op("=").Add(ret.FallbackFromDelegate(jen.Id("fallback"))).Render(buf))
+		require.Equal(t, `var fn = func(ctx context.Context, fields []string, _ error) (string, error) {
+	return fallback.MyFunction(ctx, fields...)
+}`, buf.String())
+	})
+}
+
+func TestRetryable_ResultPredicateOption(t *testing.T) {
+	errVar := types.NewVar(token.NoPos, nil, "", rtypes.ErrType)
+
+	tests := []struct {
+		name      string
+		signature *types.Signature
+		want      string
+	}{
+		{
+			name:      "Function returns error",
+			signature: types.NewSignature(nil, types.NewTuple(), types.NewTuple(errVar), false),
+			want:      "",
+		},
+		{
+			name:      "Function returns string, int and error",
+			signature: types.NewSignature(nil, types.NewTuple(), types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String]), types.NewVar(token.NoPos, nil, "", types.Typ[types.Int]), errVar), false),
+			want: `func WithResilientMyFunctionResultPredicate(fn func(string, int, error) bool) Option {
+	return func(o *base) {
+		if o.resultPredicates == nil {
+			o.resultPredicates = make(map[string]interface{})
+		}
+		o.resultPredicates[ResilientMethods.MyFunction] = fn
+	}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := method.ParseMethod("MyFunction", tt.signature)
+			require.NoError(t, err)
+			ret := retryable.NewRetryable(m, "Resilient", nil, "r")
+			s, err := ret.ResultPredicateOption()
+			require.NoError(t, err)
+			if tt.want == "" {
+				require.Nil(t, s)
+				return
+			}
+			buf := &bytes.Buffer{}
+			require.NoError(t, s.Render(buf))
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
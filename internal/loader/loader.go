@@ -4,13 +4,11 @@ import (
 	"fmt"
 	"github.com/csueiras/reinforcer/internal/generator/method"
 	"github.com/rs/zerolog/log"
-	"go/ast"
 	"go/types"
 	"golang.org/x/tools/go/packages"
 	"path/filepath"
 	"regexp"
 	"strings"
-	"unicode"
 )
 
 // LoadMode determines how a path should be loaded
@@ -34,8 +32,14 @@ const (
 	FileLoadMode
 )
 
+// regexChars are the characters that make an expression RegEx rather than an exact name
 const regexChars = "\\.+*?()|[]{}^$"
 
+// IsRegex determines whether the given expression is matched as RegEx rather than as an exact type name
+func IsRegex(expr string) bool {
+	return strings.ContainsAny(expr, regexChars)
+}
+
 // LoadingError holds any errors that occurred while loading a package
 type LoadingError struct {
 	Errors []error
@@ -52,8 +56,9 @@ func (l *LoadingError) Error() string {
 
 // Result holds the results of loading a particular type
 type Result struct {
-	Name    string
-	Methods []*method.Method
+	Name       string
+	TypeParams []*method.TypeParam
+	Methods    []*method.Method
 }
 
 // Loader is a utility service for extracting type information from a go package
@@ -169,22 +174,41 @@ func (l *Loader) loadExpr(path string, expr *regexp.Regexp, mode LoadMode) (*pac
 			return nil, nil, fmt.Errorf("%s not found in declared types of %s", typeFound, pkg)
 		}
 
+		var typeParams []*method.TypeParam
+		namedType, isNamed := types.Unalias(obj.Type()).(*types.Named)
+		if isNamed && namedType.TypeArgs().Len() == 0 {
+			typeParams, err = method.ParseTypeParams(namedType.TypeParams())
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
 		switch typ := obj.Type().Underlying().(type) {
 		case *types.Interface:
+			if !typ.IsMethodSet() {
+				log.Debug().Msgf("Ignoring matching type %s because it is a constraint interface", typeFound)
+				continue
+			}
 			logger.Info().Msgf("Discovered interface type %s", typeFound)
 			result, err := loadFromInterface(typeFound, typ)
 			if err != nil {
 				return nil, nil, err
 			}
+			result.TypeParams = typeParams
 			results[typeFound] = result
 		case *types.Struct:
+			if !isNamed {
+				log.Debug().Msgf("Ignoring matching type %s because it is not a named struct type", typeFound)
+				continue
+			}
 			logger.Info().Msgf("Discovered struct type %s", typeFound)
-			result, err := loadFromStruct(pkg.Syntax[0], typeFound, pkg.TypesInfo)
+			result, err := loadFromStruct(namedType)
 			if err != nil {
 				return nil, nil, err
 			}
 
 			if len(result.Methods) > 0 {
+				result.TypeParams = typeParams
 				results[typeFound] = result
 			}
 		default:
@@ -196,7 +220,7 @@ func (l *Loader) loadExpr(path string, expr *regexp.Regexp, mode LoadMode) (*pac
 
 func (l *Loader) load(path string, mode LoadMode) ([]*packages.Package, error) {
 	cfg := &packages.Config{
-		Mode: packages.NeedTypes | packages.NeedImports | packages.NeedSyntax | packages.NeedTypesInfo |
+		Mode: packages.NeedTypes | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypesInfo |
 			packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles,
 	}
 
@@ -237,51 +261,51 @@ func loadFromInterface(name string, interfaceType *types.Interface) (*Result, er
 	return result, nil
 }
 
-func loadFromStruct(f *ast.File, name string, info *types.Info) (*Result, error) {
+// loadFromStruct loads the methods of the struct from its method set, this includes the methods declared with pointer
+// and value receivers in any file of the package as well as the methods promoted from embedded fields
+func loadFromStruct(namedType *types.Named) (*Result, error) {
+	name := namedType.Obj().Name()
 	result := &Result{
 		Name: name,
 	}
-	var firstError error
-	ast.Inspect(f, func(node ast.Node) bool {
-		fn, ok := node.(*ast.FuncDecl)
-		if !ok {
-			return true
+
+	// Methods of generic types declare their own receiver type parameters, which may be named differently than the ones
+	// in the type declaration. Instantiating the type with its declared type parameters yields method signatures that
+	// reference the declared type parameters instead.
+	recvType := types.Type(namedType)
+	if namedType.TypeParams().Len() > 0 && namedType.TypeArgs().Len() == 0 {
+		typeArgs := make([]types.Type, namedType.TypeParams().Len())
+		for i := range typeArgs {
+			typeArgs[i] = namedType.TypeParams().At(i)
 		}
-		if fn.Recv == nil {
-			return true
+		instance, err := types.Instantiate(nil, namedType, typeArgs, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to instantiate generic type %s; error=%w", name, err)
 		}
-		for _, l := range fn.Recv.List {
-			var ident *ast.Ident
-			switch t := l.Type.(type) {
-			case *ast.StarExpr:
-				ident = t.X.(*ast.Ident)
-			case *ast.Ident:
-				ident = t
-			}
+		recvType = instance
+	}
 
-			if ident == nil || ident.Name != name {
-				continue
-			}
+	// The method set of the pointer type is a superset of the value type's method set
+	methodSet := types.NewMethodSet(types.NewPointer(recvType))
+	for i := 0; i < methodSet.Len(); i++ {
+		sel := methodSet.At(i)
+		fn := sel.Obj().(*types.Func)
 
-			// Ignore unexported methods
-			if !unicode.IsUpper(rune(fn.Name.Name[0])) {
-				log.Debug().Msgf("Ignoring function %s as it is unexported", fn.Name.Name)
-				continue
-			}
+		// Ignore unexported methods
+		if !fn.Exported() {
+			log.Debug().Msgf("Ignoring function %s as it is unexported", fn.Name())
+			continue
+		}
 
-			meth, err := method.ParseMethod(fn.Name.Name, info.Defs[fn.Name].Type().(*types.Signature))
-			if err != nil {
-				if firstError == nil {
-					firstError = err
-				}
-				return false
-			}
-			result.Methods = append(result.Methods, meth)
+		if len(sel.Index()) > 1 {
+			log.Debug().Msgf("Discovered method %s promoted from an embedded field of %s", fn.Name(), name)
 		}
-		return true
-	})
-	if firstError != nil {
-		return nil, firstError
+
+		meth, err := method.ParseMethod(fn.Name(), sel.Type().(*types.Signature))
+		if err != nil {
+			return nil, err
+		}
+		result.Methods = append(result.Methods, meth)
 	}
 	return result, nil
 }
@@ -304,7 +328,7 @@ func extractPackageErrors(pkgs []*packages.Package) error {
 func exprToFilter(expressions []string) (*regexp.Regexp, error) {
 	var filter []string
 	for _, expr := range expressions {
-		if strings.ContainsAny(expr, regexChars) {
+		if IsRegex(expr) {
 			// RegEx expression
 			filter = append(filter, expr)
 		} else {
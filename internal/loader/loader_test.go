@@ -2,6 +2,7 @@ package loader_test
 
 import (
 	"github.com/csueiras/reinforcer/internal/loader"
+	"github.com/dave/jennifer/jen"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/packages/packagestest"
@@ -120,6 +121,210 @@ func (s *service) GetUserID(ctx context.Context, userID string) (string, error)
 		require.Equal(t, 1, len(svc.Methods))
 		require.Equal(t, "GetUserID", svc.Methods[0].Name)
 	})
+
+	t.Run("Load Struct With Methods Across Files", func(t *testing.T) {
+		exported := packagestest.Export(t, packagestest.GOPATH, []packagestest.Module{{
+			Name: "github.com/csueiras",
+			Files: map[string]interface{}{
+				"fake/client.go": `package fake
+
+import "context"
+
+type Client struct {
+}
+
+func (c *Client) GetUserID(ctx context.Context, userID string) (string, error) {
+	return "My User", nil
+}
+`,
+				"fake/client_orders.go": `package fake
+
+import "context"
+
+func (c Client) GetOrderID(ctx context.Context, orderID string) (string, error) {
+	return "My Order", nil
+}
+
+func (c *Client) unexportedOperation() error {
+	return nil
+}
+`,
+			}}})
+		defer exported.Cleanup()
+
+		l := loader.NewLoader(func(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
+			exported.Config.Mode = cfg.Mode
+			return packages.Load(exported.Config, patterns...)
+		})
+
+		t.Run("Package", func(t *testing.T) {
+			client, err := l.LoadOne("github.com/csueiras/fake", "Client", loader.PackageLoadMode)
+			require.NoError(t, err)
+			require.NotNil(t, client)
+			require.Equal(t, 2, len(client.Methods))
+			require.Equal(t, "GetOrderID", client.Methods[0].Name)
+			require.Equal(t, "GetUserID", client.Methods[1].Name)
+		})
+
+		t.Run("File", func(t *testing.T) {
+			results, err := l.LoadAll(exported.File("github.com/csueiras", "fake/client.go"), loader.FileLoadMode)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(results))
+			require.Equal(t, 2, len(results["Client"].Methods))
+			require.Equal(t, "GetOrderID", results["Client"].Methods[0].Name)
+			require.Equal(t, "GetUserID", results["Client"].Methods[1].Name)
+		})
+	})
+
+	t.Run("Load Struct With Promoted Methods", func(t *testing.T) {
+		exported := packagestest.Export(t, packagestest.GOPATH, []packagestest.Module{{
+			Name: "github.com/csueiras",
+			Files: map[string]interface{}{
+				"fake/fake.go": `package fake
+
+import "context"
+
+type Closer interface {
+	Close() error
+}
+
+type helper struct {
+}
+
+func (h *helper) Ping(ctx context.Context) error {
+	return nil
+}
+
+type Client struct {
+	*helper
+	Closer
+}
+
+func (c *Client) GetUserID(ctx context.Context, userID string) (string, error) {
+	return "My User", nil
+}
+`,
+			}}})
+		defer exported.Cleanup()
+
+		l := loader.NewLoader(func(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
+			exported.Config.Mode = cfg.Mode
+			return packages.Load(exported.Config, patterns...)
+		})
+
+		client, err := l.LoadOne("github.com/csueiras/fake", "Client", loader.PackageLoadMode)
+		require.NoError(t, err)
+		require.NotNil(t, client)
+		require.Equal(t, 3, len(client.Methods))
+		require.Equal(t, "Close", client.Methods[0].Name)
+		require.Equal(t, "GetUserID", client.Methods[1].Name)
+		require.Equal(t, "Ping", client.Methods[2].Name)
+		require.True(t, client.Methods[2].HasContext)
+	})
+
+	t.Run("Load Generic Interface", func(t *testing.T) {
+		exported := packagestest.Export(t, packagestest.GOPATH, []packagestest.Module{{
+			Name: "github.com/csueiras",
+			Files: map[string]interface{}{
+				"fake/fake.go": `package fake
+
+import "context"
+
+type Repository[T any, ID comparable] interface {
+	Get(ctx context.Context, id ID) (T, error)
+}
+`,
+			}}})
+		defer exported.Cleanup()
+
+		l := loader.NewLoader(func(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
+			exported.Config.Mode = cfg.Mode
+			return packages.Load(exported.Config, patterns...)
+		})
+
+		repo, err := l.LoadOne("github.com/csueiras/fake", "Repository", loader.PackageLoadMode)
+		require.NoError(t, err)
+		require.NotNil(t, repo)
+		require.Equal(t, "Repository", repo.Name)
+		require.Equal(t, 2, len(repo.TypeParams))
+		require.Equal(t, "T", repo.TypeParams[0].Name)
+		require.Equal(t, jen.Id("any"), repo.TypeParams[0].Constraint)
+		require.Equal(t, "ID", repo.TypeParams[1].Name)
+		require.Equal(t, jen.Id("comparable"), repo.TypeParams[1].Constraint)
+		require.Equal(t, 1, len(repo.Methods))
+		require.Equal(t, "Get", repo.Methods[0].Name)
+		require.Equal(t, []jen.Code{jen.Id("T"), jen.Id("error")}, repo.Methods[0].ReturnTypes)
+	})
+
+	t.Run("Ignores Constraint Interfaces", func(t *testing.T) {
+		exported := packagestest.Export(t, packagestest.GOPATH, []packagestest.Module{{
+			Name: "github.com/csueiras",
+			Files: map[string]interface{}{
+				"fake/fake.go": `package fake
+
+import "context"
+
+type Number interface {
+	~int | ~float64
+}
+
+type Keyed interface {
+	comparable
+	Key() string
+}
+
+type Calculator[T Number] interface {
+	Sum(ctx context.Context, values ...T) (T, error)
+}
+`,
+			}}})
+		defer exported.Cleanup()
+
+		l := loader.NewLoader(func(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
+			exported.Config.Mode = cfg.Mode
+			return packages.Load(exported.Config, patterns...)
+		})
+
+		results, err := l.LoadAll("github.com/csueiras/fake", loader.PackageLoadMode)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(results))
+		require.Equal(t, "Calculator", results["Calculator"].Name)
+	})
+
+	t.Run("Load Generic Struct", func(t *testing.T) {
+		exported := packagestest.Export(t, packagestest.GOPATH, []packagestest.Module{{
+			Name: "github.com/csueiras",
+			Files: map[string]interface{}{
+				"fake/fake.go": `package fake
+
+import "context"
+
+type Repository[T any] struct {
+}
+
+func (r *Repository[V]) Get(ctx context.Context, id string) (V, error) {
+	var v V
+	return v, nil
+}
+`,
+			}}})
+		defer exported.Cleanup()
+
+		l := loader.NewLoader(func(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
+			exported.Config.Mode = cfg.Mode
+			return packages.Load(exported.Config, patterns...)
+		})
+
+		repo, err := l.LoadOne("github.com/csueiras/fake", "Repository", loader.PackageLoadMode)
+		require.NoError(t, err)
+		require.NotNil(t, repo)
+		require.Equal(t, 1, len(repo.TypeParams))
+		require.Equal(t, "T", repo.TypeParams[0].Name)
+		require.Equal(t, 1, len(repo.Methods))
+		require.Equal(t, "Get", repo.Methods[0].Name)
+		// The receiver's type parameter V is referenced through the declared type parameter T
+		require.Equal(t, []jen.Code{jen.Id("T"), jen.Id("error")}, repo.Methods[0].ReturnTypes)
+	})
 }
 
 func TestLoadMatched(t *testing.T) {
@@ -240,3 +445,11 @@ type StructWithNoMethods struct {
 		require.Equal(t, "Hello", results["HelloWorldService"].Methods[0].Name)
 	})
 }
+
+func TestIsRegex(t *testing.T) {
+	require.False(t, loader.IsRegex("Client"))
+	require.False(t, loader.IsRegex("HTTPClient2"))
+	require.True(t, loader.IsRegex(".*Service"))
+	require.True(t, loader.IsRegex("^Client$"))
+	require.True(t, loader.IsRegex("(Legacy|Old)Service"))
+}
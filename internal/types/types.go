@@ -27,7 +27,7 @@ func init() {
 
 	// Load the type definition for the Context type
 	ctxPkg, err := packages.Load(&packages.Config{
-		Mode: packages.NeedTypes | packages.NeedImports | packages.NeedSyntax | packages.NeedTypesInfo,
+		Mode: packages.NeedTypes | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypesInfo,
 	}, "context")
 	if err != nil {
 		panic(err)
@@ -58,3 +58,16 @@ func IsContextType(t types.Type) bool {
 	}
 	return types.Implements(t, ContextType)
 }
+
+// IsGRPCCallOptionType determines if the given type is grpc.CallOption, the options given to the methods of generated
+// gRPC clients
+func IsGRPCCallOptionType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Pkg().Path() == "google.golang.org/grpc" && named.Obj().Name() == "CallOption"
+}
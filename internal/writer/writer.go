@@ -26,6 +26,11 @@ func Default() *Writer {
 	return New(wio.NewFSOutputProvider(), filename.SnakeCaseStrategy())
 }
 
+// FileNameStrategy returns the strategy naming the files of the generated types
+func (w *Writer) FileNameStrategy() filename.Strategy {
+	return w.fileNameStrategy
+}
+
 // Write saves the generated contents to the given output location
 func (w *Writer) Write(outputDirectory string, generated *generator.Generated) error {
 	if err := w.writeTo(path.Join(outputDirectory, "reinforcer_common.go"), generated.Common); err != nil {
@@ -0,0 +1,46 @@
+package predicate
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// Is is a predicate that matches the errors that are any of the given errors, as reported by errors.Is
+func Is(errs ...error) Predicate {
+	return func(_ string, err error) bool {
+		for _, target := range errs {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// As is a predicate that matches the errors of type T, as reported by errors.As
+func As[T error]() Predicate {
+	return func(_ string, err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// ContextCanceled matches the errors caused by a cancelled context
+func ContextCanceled(_ string, err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// NetTimeout matches the network errors caused by a timeout
+func NetTimeout(_ string, err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Temporary matches the errors that report themselves as temporary
+func Temporary(_ string, err error) bool {
+	var tempErr interface {
+		Temporary() bool
+	}
+	return errors.As(err, &tempErr) && tempErr.Temporary()
+}
@@ -0,0 +1,63 @@
+package predicate_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/csueiras/reinforcer/pkg/predicate"
+	"github.com/stretchr/testify/require"
+	"net"
+	"os"
+	"testing"
+)
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d", e.code)
+}
+
+type temporaryError struct {
+	temporary bool
+}
+
+func (e temporaryError) Error() string {
+	return "temporary"
+}
+
+func (e temporaryError) Temporary() bool {
+	return e.temporary
+}
+
+func TestErrorMatchers(t *testing.T) {
+	errOther := errors.New("other")
+	timeoutErr := &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}
+
+	tests := []struct {
+		name      string
+		predicate predicate.Predicate
+		err       error
+		want      bool
+	}{
+		{name: "Is", predicate: predicate.Is(errOther, errFailed), err: errFailed, want: true},
+		{name: "Is Wrapped", predicate: predicate.Is(errFailed), err: fmt.Errorf("get user: %w", errFailed), want: true},
+		{name: "Is Not", predicate: predicate.Is(errFailed), err: errOther, want: false},
+		{name: "As", predicate: predicate.As[*statusError](), err: fmt.Errorf("get user: %w", &statusError{code: 503}), want: true},
+		{name: "As Not", predicate: predicate.As[*statusError](), err: errFailed, want: false},
+		{name: "Context Canceled", predicate: predicate.ContextCanceled, err: fmt.Errorf("get user: %w", context.Canceled), want: true},
+		{name: "Context Deadline Exceeded", predicate: predicate.ContextCanceled, err: context.DeadlineExceeded, want: false},
+		{name: "Net Timeout", predicate: predicate.NetTimeout, err: fmt.Errorf("get user: %w", timeoutErr), want: true},
+		{name: "Net Error Without Timeout", predicate: predicate.NetTimeout, err: &net.OpError{Op: "dial", Err: errFailed}, want: false},
+		{name: "Net Timeout Not A Net Error", predicate: predicate.NetTimeout, err: errFailed, want: false},
+		{name: "Temporary", predicate: predicate.Temporary, err: fmt.Errorf("get user: %w", temporaryError{temporary: true}), want: true},
+		{name: "Not Temporary", predicate: predicate.Temporary, err: temporaryError{temporary: false}, want: false},
+		{name: "Temporary Not Reported", predicate: predicate.Temporary, err: errFailed, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.predicate("Client.GetUser", tt.err))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Package grpcstatus provides predicates that classify the errors of gRPC calls by their status code, these are meant
+// for the proxies of generated gRPC clients:
+//
+//	reinforced.NewGreeterClient(pb.NewGreeterClient(conn), r, reinforced.WithRetryableErrorPredicate(grpcstatus.Retryable))
+package grpcstatus
+
+import (
+	"github.com/csueiras/reinforcer/pkg/predicate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryableCodes are the status codes of the calls that are worth retrying
+var retryableCodes = Codes(codes.Unavailable, codes.DeadlineExceeded)
+
+// Codes is a predicate that matches the errors carrying any of the given status codes, the status is found in wrapped
+// errors too
+func Codes(cs ...codes.Code) predicate.Predicate {
+	return func(_ string, err error) bool {
+		if err == nil {
+			return false
+		}
+		code := status.Code(err)
+		for _, c := range cs {
+			if code == c {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Retryable matches the errors of the calls that are worth retrying, those with the Unavailable and DeadlineExceeded
+// status codes. The errors with other codes, such as InvalidArgument, are never retried.
+func Retryable(method string, err error) bool {
+	return retryableCodes(method, err)
+}
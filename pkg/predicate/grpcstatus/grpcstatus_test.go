@@ -0,0 +1,93 @@
+package grpcstatus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/csueiras/reinforcer/pkg/predicate/grpcstatus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"strconv"
+	"testing"
+)
+
+// healthServer fails the checks with the status code given as the name of the service being checked
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (s *healthServer) Check(_ context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	code, err := strconv.Atoi(req.Service)
+	if err != nil {
+		return nil, err
+	}
+	if code := codes.Code(code); code != codes.OK {
+		return nil, status.Error(code, "check failed")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+// newHealthClient starts an in-process health server and creates a client connected to it
+func newHealthClient(t *testing.T) grpc_health_v1.HealthClient {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, &healthServer{})
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestRetryable(t *testing.T) {
+	client := newHealthClient(t)
+
+	tests := []struct {
+		code codes.Code
+		want bool
+	}{
+		{code: codes.Unavailable, want: true},
+		{code: codes.DeadlineExceeded, want: true},
+		{code: codes.InvalidArgument, want: false},
+		{code: codes.NotFound, want: false},
+		{code: codes.Internal, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: strconv.Itoa(int(tt.code))})
+			require.Error(t, err)
+			require.Equal(t, tt.want, grpcstatus.Retryable("HealthClient.Check", err))
+			require.Equal(t, tt.want, grpcstatus.Retryable("HealthClient.Check", fmt.Errorf("check: %w", err)))
+		})
+	}
+
+	t.Run("Not A Status", func(t *testing.T) {
+		require.False(t, grpcstatus.Retryable("HealthClient.Check", errors.New("failed")))
+		require.False(t, grpcstatus.Retryable("HealthClient.Check", nil))
+	})
+}
+
+func TestCodes(t *testing.T) {
+	p := grpcstatus.Codes(codes.NotFound, codes.Aborted)
+	require.True(t, p("HealthClient.Check", status.Error(codes.NotFound, "not found")))
+	require.True(t, p("HealthClient.Check", status.Error(codes.Aborted, "aborted")))
+	require.False(t, p("HealthClient.Check", status.Error(codes.Unavailable, "unavailable")))
+	require.False(t, grpcstatus.Codes(codes.OK)("HealthClient.Check", nil))
+}
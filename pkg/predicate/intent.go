@@ -0,0 +1,46 @@
+package predicate
+
+import "errors"
+
+// intentError is an error whose retryability was stated by the delegate that returned it
+type intentError struct {
+	err       error
+	retryable bool
+}
+
+func (e *intentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *intentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps the given error stating that it must not be retried, nil is returned for a nil error
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &intentError{err: err, retryable: false}
+}
+
+// Retryable wraps the given error stating that it should be retried, nil is returned for a nil error
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &intentError{err: err, retryable: true}
+}
+
+// Explicit is a predicate that honors the intent stated by the delegates with Permanent and Retryable, the errors
+// without a stated intent are classified by the given predicate. When the error was wrapped multiple times the outermost
+// intent is honored.
+func Explicit(p Predicate) Predicate {
+	return func(method string, err error) bool {
+		var intentErr *intentError
+		if errors.As(err, &intentErr) {
+			return intentErr.retryable
+		}
+		return p(method, err)
+	}
+}
@@ -0,0 +1,43 @@
+package predicate_test
+
+import (
+	"fmt"
+	"github.com/csueiras/reinforcer/pkg/predicate"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPermanent(t *testing.T) {
+	err := predicate.Permanent(errFailed)
+	require.ErrorIs(t, err, errFailed)
+	require.Equal(t, errFailed.Error(), err.Error())
+	require.NoError(t, predicate.Permanent(nil))
+}
+
+func TestRetryable(t *testing.T) {
+	err := predicate.Retryable(errFailed)
+	require.ErrorIs(t, err, errFailed)
+	require.Equal(t, errFailed.Error(), err.Error())
+	require.NoError(t, predicate.Retryable(nil))
+}
+
+func TestExplicit(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate predicate.Predicate
+		err       error
+		want      bool
+	}{
+		{name: "Permanent", predicate: always, err: predicate.Permanent(errFailed), want: false},
+		{name: "Permanent Wrapped", predicate: always, err: fmt.Errorf("get user: %w", predicate.Permanent(errFailed)), want: false},
+		{name: "Retryable", predicate: never, err: predicate.Retryable(errFailed), want: true},
+		{name: "Outermost Intent", predicate: never, err: predicate.Retryable(predicate.Permanent(errFailed)), want: true},
+		{name: "No Intent Retried", predicate: always, err: errFailed, want: true},
+		{name: "No Intent Not Retried", predicate: never, err: errFailed, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, predicate.Explicit(tt.predicate)("Client.GetUser", tt.err))
+		})
+	}
+}
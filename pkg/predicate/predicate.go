@@ -0,0 +1,60 @@
+// Package predicate provides composable predicates that classify the errors of the reinforced proxies as retryable,
+// these can be given to the proxies with WithRetryableErrorPredicate:
+//
+//	reinforced.WithRetryableErrorPredicate(predicate.And(
+//	    predicate.Except(reinforced.ClientMethods.SaveUser),
+//	    predicate.Or(predicate.NetTimeout, predicate.Is(client.ErrUnavailable)),
+//	))
+package predicate
+
+// Predicate determines whether the error returned by the given method should be retried
+type Predicate func(method string, err error) bool
+
+// And is a predicate that matches when all the given predicates match
+func And(predicates ...Predicate) Predicate {
+	return func(method string, err error) bool {
+		for _, p := range predicates {
+			if !p(method, err) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or is a predicate that matches when any of the given predicates matches
+func Or(predicates ...Predicate) Predicate {
+	return func(method string, err error) bool {
+		for _, p := range predicates {
+			if p(method, err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not is a predicate that matches when the given predicate doesn't
+func Not(p Predicate) Predicate {
+	return func(method string, err error) bool {
+		return !p(method, err)
+	}
+}
+
+// ForMethods is a predicate that matches the errors of the given methods, the methods are named by the generated
+// constants (e.g. reinforced.ClientMethods.GetUser)
+func ForMethods(methods ...string) Predicate {
+	set := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		set[m] = struct{}{}
+	}
+	return func(method string, _ error) bool {
+		_, ok := set[method]
+		return ok
+	}
+}
+
+// Except is a predicate that matches the errors of all methods but the given ones
+func Except(methods ...string) Predicate {
+	return Not(ForMethods(methods...))
+}
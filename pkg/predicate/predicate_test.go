@@ -0,0 +1,61 @@
+package predicate_test
+
+import (
+	"errors"
+	"github.com/csueiras/reinforcer/pkg/predicate"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var errFailed = errors.New("failed")
+
+func always(_ string, _ error) bool {
+	return true
+}
+
+func never(_ string, _ error) bool {
+	return false
+}
+
+func TestCombinators(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate predicate.Predicate
+		want      bool
+	}{
+		{name: "And All Match", predicate: predicate.And(always, always), want: true},
+		{name: "And One Doesn't Match", predicate: predicate.And(always, never), want: false},
+		{name: "And Empty", predicate: predicate.And(), want: true},
+		{name: "Or One Matches", predicate: predicate.Or(never, always), want: true},
+		{name: "Or None Match", predicate: predicate.Or(never, never), want: false},
+		{name: "Or Empty", predicate: predicate.Or(), want: false},
+		{name: "Not", predicate: predicate.Not(always), want: false},
+		{name: "Nested", predicate: predicate.And(predicate.Not(never), predicate.Or(never, always)), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.predicate("Client.GetUser", errFailed))
+		})
+	}
+}
+
+func TestForMethods(t *testing.T) {
+	p := predicate.ForMethods("Client.GetUser", "Client.SaveUser")
+	require.True(t, p("Client.GetUser", errFailed))
+	require.True(t, p("Client.SaveUser", errFailed))
+	require.False(t, p("Client.DeleteUser", errFailed))
+	require.False(t, predicate.ForMethods()("Client.GetUser", errFailed))
+}
+
+func TestExcept(t *testing.T) {
+	p := predicate.Except("Client.SaveUser")
+	require.True(t, p("Client.GetUser", errFailed))
+	require.False(t, p("Client.SaveUser", errFailed))
+}
+
+func TestPredicate_RetryableErrorPredicate(t *testing.T) {
+	// Predicates can be given wherever a func(method string, err error) bool is expected
+	var retryableErrorPredicate func(string, error) bool = predicate.And(predicate.Except("Client.SaveUser"), predicate.Is(errFailed))
+	require.True(t, retryableErrorPredicate("Client.GetUser", errFailed))
+	require.False(t, retryableErrorPredicate("Client.SaveUser", errFailed))
+}
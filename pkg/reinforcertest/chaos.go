@@ -0,0 +1,83 @@
+package reinforcertest
+
+import (
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// ChaosOption configures the Chaos
+type ChaosOption func(c *Chaos)
+
+// WithFault injects the given fault into the runner of the given name (e.g. reinforced.ClientMethods.GetUser)
+func WithFault(name string, fault runner.Fault) ChaosOption {
+	return func(c *Chaos) {
+		c.faults[name] = fault
+	}
+}
+
+// WithDefaultFault injects the given fault into the runners without a fault of their own
+func WithDefaultFault(fault runner.Fault) ChaosOption {
+	return func(c *Chaos) {
+		c.defaultFault = &fault
+	}
+}
+
+// Chaos is a runner.FaultSource that injects faults into the attempts of the runners by name, its middleware is given
+// to runner.Wrap to inject the faults into the runners of any factory. The runners draw from their own source seeded by
+// the Chaos' seed and their name, so the faults injected into the sequential calls of a runner are the same in every run.
+//
+// The Chaos must wrap a Recorder, rather than be wrapped by it, for the injected faults to be recorded as attempts.
+type Chaos struct {
+	seed         int64
+	faults       map[string]runner.Fault
+	defaultFault *runner.Fault
+	mu           sync.Mutex
+	sources      map[string]*rand.Rand
+}
+
+// NewChaos creates a Chaos that injects the faults drawn from the given seed
+func NewChaos(seed int64, options ...ChaosOption) *Chaos {
+	c := &Chaos{
+		seed:    seed,
+		faults:  make(map[string]runner.Fault),
+		sources: make(map[string]*rand.Rand),
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+// Middleware creates the middleware that injects the faults into the runner with the given name, it's a
+// runner.NamedMiddleware to be given to runner.Wrap or runner.NewNamedFactory
+func (c *Chaos) Middleware(name string) goresilience.Middleware {
+	return runner.FaultMiddleware(name, c)
+}
+
+// Fault returns the fault injected into the attempts of the runner with the given name
+func (c *Chaos) Fault(name string) (runner.Fault, bool) {
+	if fault, ok := c.faults[name]; ok {
+		return fault, true
+	}
+	if c.defaultFault == nil {
+		return runner.Fault{}, false
+	}
+	return *c.defaultFault, true
+}
+
+// Float64 returns the next random number from the source of the runner with the given name. This is thread-safe.
+func (c *Chaos) Float64(name string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	src, ok := c.sources[name]
+	if !ok {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(name))
+		src = rand.New(rand.NewSource(c.seed ^ int64(h.Sum64())))
+		c.sources[name] = src
+	}
+	return src.Float64()
+}
@@ -0,0 +1,134 @@
+package reinforcertest_test
+
+import (
+	"context"
+	"errors"
+	"github.com/csueiras/reinforcer/example/client/reinforced"
+	"github.com/csueiras/reinforcer/pkg/reinforcertest"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience"
+	"github.com/slok/goresilience/retry"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// outcomes runs the runner the given times and returns whether each call succeeded
+func outcomes(f reinforcertest.RunnerFactory, name string, times int) []bool {
+	r := f.GetRunner(name)
+	var got []bool
+	for i := 0; i < times; i++ {
+		got = append(got, r.Run(context.Background(), func(ctx context.Context) error {
+			return nil
+		}) == nil)
+	}
+	return got
+}
+
+func TestChaos_Errors(t *testing.T) {
+	fault := reinforcertest.WithFault(reinforced.ClientMethods.SayHello, runner.Fault{ErrorRate: 0.5})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		first := outcomes(runner.Wrap(runner.NewFactory(), reinforcertest.NewChaos(42, fault).Middleware), reinforced.ClientMethods.SayHello, 100)
+		second := outcomes(runner.Wrap(runner.NewFactory(), reinforcertest.NewChaos(42, fault).Middleware), reinforced.ClientMethods.SayHello, 100)
+		require.Equal(t, first, second)
+		require.Contains(t, first, true)
+		require.Contains(t, first, false)
+
+		other := outcomes(runner.Wrap(runner.NewFactory(), reinforcertest.NewChaos(7, fault).Middleware), reinforced.ClientMethods.SayHello, 100)
+		require.NotEqual(t, first, other)
+	})
+
+	t.Run("Other Runners Are Not Affected", func(t *testing.T) {
+		f := runner.Wrap(runner.NewFactory(), reinforcertest.NewChaos(42, fault).Middleware)
+		for _, ok := range outcomes(f, reinforced.ClientMethods.GenerateGreeting, 100) {
+			require.True(t, ok)
+		}
+	})
+
+	t.Run("Default Fault", func(t *testing.T) {
+		chaos := reinforcertest.NewChaos(42, fault, reinforcertest.WithDefaultFault(runner.Fault{ErrorRate: 1}))
+		for _, ok := range outcomes(runner.Wrap(runner.NewFactory(), chaos.Middleware), reinforced.ClientMethods.GenerateGreeting, 100) {
+			require.False(t, ok)
+		}
+	})
+
+	t.Run("Custom Error", func(t *testing.T) {
+		errCustom := errors.New("custom")
+		chaos := reinforcertest.NewChaos(42, reinforcertest.WithFault(reinforced.ClientMethods.SayHello, runner.Fault{ErrorRate: 1, Err: errCustom}))
+		c := reinforced.NewClient(&fakeClient{}, runner.Wrap(runner.NewFactory(), chaos.Middleware))
+		require.ErrorIs(t, c.SayHello(context.Background(), "Reinforcer"), errCustom)
+	})
+}
+
+func TestChaos_Retried(t *testing.T) {
+	rec := reinforcertest.NewRecorder(retrying(5))
+	chaos := reinforcertest.NewChaos(1, reinforcertest.WithFault(reinforced.ClientMethods.GenerateGreeting, runner.Fault{ErrorRate: 0.5}))
+	delegate := &fakeClient{}
+	c := reinforced.NewClient(delegate, runner.Wrap(rec, chaos.Middleware))
+
+	for i := 0; i < 20; i++ {
+		_, err := c.GenerateGreeting(context.Background(), "Reinforcer")
+		require.NoError(t, err)
+	}
+
+	injected := 0
+	for _, call := range rec.CallsOf(reinforced.ClientMethods.GenerateGreeting) {
+		for _, a := range call.Attempts {
+			if errors.Is(a.Err, runner.ErrChaos) {
+				injected++
+			}
+		}
+	}
+	require.Greater(t, injected, 0)
+	require.Equal(t, 20, delegate.calls, "the delegate is only called by the attempts without a fault")
+}
+
+func TestChaos_Latency(t *testing.T) {
+	chaos := reinforcertest.NewChaos(42, reinforcertest.WithFault(reinforced.ClientMethods.SayHello, runner.Fault{
+		Latency:     20 * time.Millisecond,
+		LatencyRate: 1,
+	}))
+	c := reinforced.NewClient(&fakeClient{}, runner.Wrap(runner.NewFactory(), chaos.Middleware))
+
+	start := time.Now()
+	require.NoError(t, c.SayHello(context.Background(), "Reinforcer"))
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, c.SayHello(ctx, "Reinforcer"), context.DeadlineExceeded)
+}
+
+func TestChaos_Panics(t *testing.T) {
+	rec := reinforcertest.NewRecorder(runner.NewFactory())
+	chaos := reinforcertest.NewChaos(42, reinforcertest.WithFault(reinforced.ClientMethods.SayHello, runner.Fault{PanicRate: 1}))
+	c := reinforced.NewClient(&fakeClient{}, runner.Wrap(rec, chaos.Middleware))
+
+	require.PanicsWithValue(t, runner.ErrChaosPanic, func() {
+		_ = c.SayHello(context.Background(), "Reinforcer")
+	})
+
+	calls := rec.Calls()
+	require.Len(t, calls, 1)
+	require.Len(t, calls[0].Attempts, 1)
+	require.Equal(t, runner.ErrChaosPanic, calls[0].Attempts[0].Panic)
+}
+
+func TestChaos_Chain(t *testing.T) {
+	chaos := reinforcertest.NewChaos(42, reinforcertest.WithFault(reinforced.ClientMethods.SayHello, runner.Fault{ErrorRate: 1}))
+	attempts := 0
+	counted := func(next goresilience.Runner) goresilience.Runner {
+		return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+			return next.Run(ctx, func(ctx context.Context) error {
+				attempts++
+				return f(ctx)
+			})
+		})
+	}
+	f := runner.NewNamedFactory(runner.Unnamed(retry.NewMiddleware(retry.Config{Times: 2, WaitBase: time.Millisecond})), chaos.Middleware, runner.Unnamed(counted))
+	c := reinforced.NewClient(&fakeClient{}, f)
+
+	require.ErrorIs(t, c.SayHello(context.Background(), "Reinforcer"), runner.ErrChaos)
+	require.Equal(t, 3, attempts, "the faults are injected into every attempt")
+}
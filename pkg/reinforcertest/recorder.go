@@ -0,0 +1,126 @@
+// Package reinforcertest provides tools for testing the predicates and the policies of the reinforced proxies without a
+// flaky backend, the Recorder records the calls going through the runners and the Chaos injects faults into them:
+//
+//	rec := reinforcertest.NewRecorder(runner.NewFactory(retry.NewMiddleware(retry.Config{Times: 2})))
+//	chaos := reinforcertest.NewChaos(42, reinforcertest.WithFault(reinforced.ClientMethods.GetUser, runner.Fault{ErrorRate: 0.5}))
+//	c := reinforced.NewClient(delegate, runner.Wrap(rec, chaos.Middleware))
+package reinforcertest
+
+import (
+	"context"
+	"github.com/slok/goresilience"
+	"sync"
+	"time"
+)
+
+// RunnerFactory creates runners by name, it is satisfied by runner.Factory as well as by the Recorder
+type RunnerFactory interface {
+	GetRunner(name string) goresilience.Runner
+}
+
+// Attempt is a single execution of the delegate's call by a runner
+type Attempt struct {
+	// Err is the error returned by the attempt
+	Err error
+	// Panic is the value the attempt panicked with, nil if it didn't panic
+	Panic interface{}
+	// Duration is the time the attempt took
+	Duration time.Duration
+}
+
+// Call is a call that went through a runner
+type Call struct {
+	// Name is the name of the runner
+	Name string
+	// Attempts are the executions of the delegate's call in order, these can be fewer than the retries configured when
+	// a middleware rejects the call (e.g. an open circuit breaker)
+	Attempts []Attempt
+	// Err is the error returned by the runner
+	Err error
+	// Duration is the time the call took, including the middlewares
+	Duration time.Duration
+}
+
+// Recorder is a runner factory that records the calls going through the runners of another factory
+type Recorder struct {
+	runnerFactory RunnerFactory
+	mu            sync.Mutex
+	calls         []Call
+}
+
+// NewRecorder creates a Recorder of the runners created by the given factory
+func NewRecorder(runnerFactory RunnerFactory) *Recorder {
+	if runnerFactory == nil {
+		panic("provided nil runner factory")
+	}
+	return &Recorder{runnerFactory: runnerFactory}
+}
+
+// GetRunner returns the runner of the given name from the underlying factory, the calls going through it are recorded
+func (r *Recorder) GetRunner(name string) goresilience.Runner {
+	next := r.runnerFactory.GetRunner(name)
+	return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) (err error) {
+		var mu sync.Mutex
+		call := Call{Name: name}
+		start := time.Now()
+		defer func() {
+			mu.Lock()
+			defer mu.Unlock()
+			call.Err = err
+			call.Duration = time.Since(start)
+			// Attempts abandoned by a middleware (e.g. a timeout) may still finish after the call
+			call.Attempts = append([]Attempt(nil), call.Attempts...)
+			r.record(call)
+		}()
+
+		return next.Run(ctx, func(ctx context.Context) (err error) {
+			attempt := Attempt{}
+			attemptStart := time.Now()
+			defer func() {
+				attempt.Err = err
+				attempt.Duration = time.Since(attemptStart)
+				if p := recover(); p != nil {
+					attempt.Panic = p
+					defer panic(p)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				call.Attempts = append(call.Attempts, attempt)
+			}()
+			return f(ctx)
+		})
+	})
+}
+
+// Calls returns the calls recorded in the order these finished
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsOf returns the calls recorded for the runner of the given name in the order these finished
+func (r *Recorder) CallsOf(name string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var calls []Call
+	for _, c := range r.calls {
+		if c.Name == name {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset discards the calls recorded
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+func (r *Recorder) record(call Call) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
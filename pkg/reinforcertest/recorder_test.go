@@ -0,0 +1,105 @@
+package reinforcertest_test
+
+import (
+	"context"
+	"errors"
+	"github.com/csueiras/reinforcer/example/client/reinforced"
+	"github.com/csueiras/reinforcer/pkg/reinforcertest"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience/retry"
+	"github.com/slok/goresilience/timeout"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	errUnavailable = errors.New("unavailable")
+	errNotFound    = errors.New("not found")
+)
+
+// fakeClient fails with the given errors in order before succeeding
+type fakeClient struct {
+	mu    sync.Mutex
+	errs  []error
+	calls int
+}
+
+func (c *fakeClient) GenerateGreeting(_ context.Context, name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return "", err
+	}
+	return "Hello " + name, nil
+}
+
+func (c *fakeClient) SayHello(ctx context.Context, name string) error {
+	_, err := c.GenerateGreeting(ctx, name)
+	return err
+}
+
+func retrying(times int) *runner.Factory {
+	return runner.NewFactory(retry.NewMiddleware(retry.Config{Times: times, WaitBase: time.Millisecond}))
+}
+
+func TestRecorder(t *testing.T) {
+	rec := reinforcertest.NewRecorder(retrying(2))
+	delegate := &fakeClient{errs: []error{errUnavailable, errUnavailable}}
+	c := reinforced.NewClient(delegate, rec, reinforced.WithRetryableErrorPredicate(func(method string, err error) bool {
+		return !errors.Is(err, errNotFound)
+	}))
+
+	// Retried until it succeeds
+	got, err := c.GenerateGreeting(context.Background(), "Reinforcer")
+	require.NoError(t, err)
+	require.Equal(t, "Hello Reinforcer", got)
+
+	// Not retried
+	delegate.errs = []error{errNotFound}
+	require.ErrorIs(t, c.SayHello(context.Background(), "Reinforcer"), errNotFound)
+
+	calls := rec.Calls()
+	require.Len(t, calls, 2)
+
+	require.Equal(t, reinforced.ClientMethods.GenerateGreeting, calls[0].Name)
+	require.NoError(t, calls[0].Err)
+	require.Len(t, calls[0].Attempts, 3)
+	require.ErrorIs(t, calls[0].Attempts[0].Err, errUnavailable)
+	require.ErrorIs(t, calls[0].Attempts[1].Err, errUnavailable)
+	require.NoError(t, calls[0].Attempts[2].Err)
+	require.GreaterOrEqual(t, calls[0].Duration, calls[0].Attempts[0].Duration+calls[0].Attempts[1].Duration)
+
+	require.Equal(t, reinforced.ClientMethods.SayHello, calls[1].Name)
+	require.Len(t, calls[1].Attempts, 1)
+
+	require.Equal(t, calls[1:], rec.CallsOf(reinforced.ClientMethods.SayHello))
+	require.Empty(t, rec.CallsOf("Client.Unknown"))
+
+	rec.Reset()
+	require.Empty(t, rec.Calls())
+}
+
+func TestRecorder_Timeout(t *testing.T) {
+	rec := reinforcertest.NewRecorder(runner.NewFactory(timeout.NewMiddleware(timeout.Config{Timeout: 5 * time.Millisecond})))
+	err := rec.GetRunner("Client.SayHello").Run(context.Background(), func(ctx context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	require.Error(t, err)
+
+	calls := rec.Calls()
+	require.Len(t, calls, 1)
+	require.Error(t, calls[0].Err)
+	require.Empty(t, calls[0].Attempts, "the attempt hadn't finished")
+}
+
+func TestNewRecorder(t *testing.T) {
+	require.Panics(t, func() {
+		reinforcertest.NewRecorder(nil)
+	})
+}
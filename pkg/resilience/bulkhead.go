@@ -0,0 +1,52 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultBulkheadWorkers = 15
+
+// ErrBulkheadFull is the error returned when an execution can't be started by a bulkhead within its maximum wait time
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// BulkheadConfig is the configuration of the Bulkhead middleware
+type BulkheadConfig struct {
+	// Workers is the maximum number of concurrent executions, defaults to 15
+	Workers int
+	// MaxWaitTime is the maximum time an execution waits to be started, when zero the execution waits until its context
+	// is done
+	MaxWaitTime time.Duration
+}
+
+// Bulkhead creates a middleware that limits the number of concurrent executions, the executions that can't be started
+// within the maximum wait time are rejected with ErrBulkheadFull
+func Bulkhead(cfg BulkheadConfig) Middleware {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultBulkheadWorkers
+	}
+	return func(next Runner) Runner {
+		workers := make(chan struct{}, cfg.Workers)
+		return RunnerFunc(func(ctx context.Context, fn Func) error {
+			var timeout <-chan time.Time
+			if cfg.MaxWaitTime > 0 {
+				timer := time.NewTimer(cfg.MaxWaitTime)
+				defer timer.Stop()
+				timeout = timer.C
+			}
+
+			select {
+			case workers <- struct{}{}:
+			case <-timeout:
+				return ErrBulkheadFull
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() {
+				<-workers
+			}()
+			return next.Run(ctx, fn)
+		})
+	}
+}
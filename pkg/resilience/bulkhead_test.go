@@ -0,0 +1,80 @@
+package resilience_test
+
+import (
+	"context"
+	"github.com/csueiras/reinforcer/pkg/resilience"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBulkhead(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Limits Concurrent Executions", func(t *testing.T) {
+		r := resilience.Chain(resilience.Bulkhead(resilience.BulkheadConfig{Workers: 2}))
+		var running, maxRunning atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				require.NoError(t, r.Run(ctx, func(ctx context.Context) error {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						m := maxRunning.Load()
+						if n <= m || maxRunning.CompareAndSwap(m, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					return nil
+				}))
+			}()
+		}
+		wg.Wait()
+		require.LessOrEqual(t, maxRunning.Load(), int64(2))
+	})
+
+	t.Run("Max Wait Time", func(t *testing.T) {
+		r := resilience.Chain(resilience.Bulkhead(resilience.BulkheadConfig{Workers: 1, MaxWaitTime: 5 * time.Millisecond}))
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- r.Run(ctx, func(ctx context.Context) error {
+				close(started)
+				<-unblock
+				return nil
+			})
+		}()
+		<-started
+
+		require.ErrorIs(t, r.Run(ctx, succeeding), resilience.ErrBulkheadFull)
+		close(unblock)
+		require.NoError(t, <-done)
+		require.NoError(t, r.Run(ctx, succeeding))
+	})
+
+	t.Run("Context Done", func(t *testing.T) {
+		r := resilience.Chain(resilience.Bulkhead(resilience.BulkheadConfig{Workers: 1}))
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		defer close(unblock)
+		go func() {
+			_ = r.Run(ctx, func(ctx context.Context) error {
+				close(started)
+				<-unblock
+				return nil
+			})
+		}()
+		<-started
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, r.Run(timeoutCtx, succeeding), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,166 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultErrorPercentThresholdToOpen  = 50
+	defaultMinimumRequestToOpen         = 20
+	defaultSuccessfulRequiredOnHalfOpen = 1
+	defaultWaitDurationInOpenState      = 5 * time.Second
+	defaultCircuitBreakerWindow         = 10 * time.Second
+)
+
+// ErrCircuitOpen is the error returned when the execution is rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerConfig is the configuration of the CircuitBreaker middleware
+type CircuitBreakerConfig struct {
+	// ErrorPercentThresholdToOpen is the percentage of failed executions that opens the circuit, defaults to 50
+	ErrorPercentThresholdToOpen int
+	// MinimumRequestToOpen is the number of executions required within the window before the circuit can open,
+	// defaults to 20
+	MinimumRequestToOpen int
+	// SuccessfulRequiredOnHalfOpen is the number of successful executions required to close a half-open circuit, it's
+	// also the number of executions a half-open circuit lets through at once, defaults to 1
+	SuccessfulRequiredOnHalfOpen int
+	// WaitDurationInOpenState is the duration the circuit remains open before letting executions through, defaults to
+	// 5s
+	WaitDurationInOpenState time.Duration
+	// Window is the duration over which the executions of a closed circuit are measured, defaults to 10s
+	Window time.Duration
+}
+
+func (c *CircuitBreakerConfig) defaults() {
+	if c.ErrorPercentThresholdToOpen <= 0 {
+		c.ErrorPercentThresholdToOpen = defaultErrorPercentThresholdToOpen
+	}
+	if c.MinimumRequestToOpen <= 0 {
+		c.MinimumRequestToOpen = defaultMinimumRequestToOpen
+	}
+	if c.SuccessfulRequiredOnHalfOpen <= 0 {
+		c.SuccessfulRequiredOnHalfOpen = defaultSuccessfulRequiredOnHalfOpen
+	}
+	if c.WaitDurationInOpenState <= 0 {
+		c.WaitDurationInOpenState = defaultWaitDurationInOpenState
+	}
+	if c.Window <= 0 {
+		c.Window = defaultCircuitBreakerWindow
+	}
+}
+
+type circuitState int
+
+const (
+	stateClosed circuitState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// circuitBreaker holds the state of the circuit of a single runner
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       circuitState
+	windowStart time.Time
+	openedAt    time.Time
+	total       int
+	failed      int
+	successes   int
+	probes      int
+}
+
+// allow determines whether an execution can go through the circuit, the executions let through by a half-open circuit
+// are probes and are identified by the time the circuit was opened at
+func (cb *circuitBreaker) allow(now time.Time) (bool, time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case stateOpen:
+		if now.Sub(cb.openedAt) < cb.cfg.WaitDurationInOpenState {
+			return false, time.Time{}
+		}
+		cb.state = stateHalfOpen
+		cb.successes = 0
+		cb.probes = 0
+	case stateClosed:
+		return true, time.Time{}
+	}
+	if cb.probes >= cb.cfg.SuccessfulRequiredOnHalfOpen {
+		return false, time.Time{}
+	}
+	cb.probes++
+	return true, cb.openedAt
+}
+
+// record updates the state of the circuit with the result of an execution, probe is the time the circuit was opened at
+// when the execution is a probe
+func (cb *circuitBreaker) record(now time.Time, probe time.Time, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case stateHalfOpen:
+		// The probes of a previous half-open circuit aren't in flight for this one
+		if probe.Equal(cb.openedAt) {
+			cb.probes--
+		}
+		if err != nil {
+			cb.open(now)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.cfg.SuccessfulRequiredOnHalfOpen {
+			cb.close(now)
+		}
+	case stateClosed:
+		if now.Sub(cb.windowStart) >= cb.cfg.Window {
+			cb.close(now)
+		}
+		cb.total++
+		if err == nil {
+			return
+		}
+		cb.failed++
+		if cb.total >= cb.cfg.MinimumRequestToOpen && cb.failed*100 >= cb.cfg.ErrorPercentThresholdToOpen*cb.total {
+			cb.open(now)
+		}
+	}
+}
+
+func (cb *circuitBreaker) open(now time.Time) {
+	cb.state = stateOpen
+	cb.openedAt = now
+}
+
+func (cb *circuitBreaker) close(now time.Time) {
+	cb.state = stateClosed
+	cb.windowStart = now
+	cb.total = 0
+	cb.failed = 0
+}
+
+// CircuitBreaker creates a middleware that stops executing the function once too many executions fail, the executions
+// are rejected with ErrCircuitOpen until the circuit lets a limited number of executions through again to probe whether
+// these succeed
+func CircuitBreaker(cfg CircuitBreakerConfig) Middleware {
+	cfg.defaults()
+	return func(next Runner) Runner {
+		cb := &circuitBreaker{cfg: cfg, windowStart: time.Now()}
+		return RunnerFunc(func(ctx context.Context, fn Func) error {
+			ok, probe := cb.allow(time.Now())
+			if !ok {
+				return ErrCircuitOpen
+			}
+			err := next.Run(ctx, fn)
+			cb.record(time.Now(), probe, err)
+			return err
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package resilience_test
+
+import (
+	"context"
+	"github.com/csueiras/reinforcer/pkg/resilience"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	cfg := resilience.CircuitBreakerConfig{
+		ErrorPercentThresholdToOpen:  50,
+		MinimumRequestToOpen:         4,
+		SuccessfulRequiredOnHalfOpen: 2,
+		WaitDurationInOpenState:      20 * time.Millisecond,
+	}
+	ctx := context.Background()
+
+	t.Run("Opens On Errors", func(t *testing.T) {
+		r := resilience.Chain(resilience.CircuitBreaker(cfg))
+		calls := 0
+		require.NoError(t, r.Run(ctx, succeeding))
+		require.NoError(t, r.Run(ctx, succeeding))
+		require.ErrorIs(t, r.Run(ctx, failing(&calls)), errFailed)
+		require.ErrorIs(t, r.Run(ctx, failing(&calls)), errFailed)
+
+		require.ErrorIs(t, r.Run(ctx, failing(&calls)), resilience.ErrCircuitOpen)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Stays Closed Below Threshold", func(t *testing.T) {
+		r := resilience.Chain(resilience.CircuitBreaker(cfg))
+		calls := 0
+		for i := 0; i < 10; i++ {
+			require.NoError(t, r.Run(ctx, succeeding))
+			require.NoError(t, r.Run(ctx, succeeding))
+			require.ErrorIs(t, r.Run(ctx, failing(&calls)), errFailed)
+		}
+		require.Equal(t, 10, calls)
+	})
+
+	t.Run("Half Open", func(t *testing.T) {
+		r := resilience.Chain(resilience.CircuitBreaker(cfg))
+		calls := 0
+		for i := 0; i < 4; i++ {
+			require.ErrorIs(t, r.Run(ctx, failing(&calls)), errFailed)
+		}
+		require.ErrorIs(t, r.Run(ctx, succeeding), resilience.ErrCircuitOpen)
+
+		// A failure while half-open opens the circuit again
+		time.Sleep(cfg.WaitDurationInOpenState)
+		require.ErrorIs(t, r.Run(ctx, failing(&calls)), errFailed)
+		require.ErrorIs(t, r.Run(ctx, succeeding), resilience.ErrCircuitOpen)
+
+		// Enough successes while half-open close the circuit
+		time.Sleep(cfg.WaitDurationInOpenState)
+		require.NoError(t, r.Run(ctx, succeeding))
+		require.NoError(t, r.Run(ctx, succeeding))
+		require.ErrorIs(t, r.Run(ctx, failing(&calls)), errFailed)
+		require.NoError(t, r.Run(ctx, succeeding))
+	})
+
+	t.Run("Half Open Limits Concurrent Probes", func(t *testing.T) {
+		r := resilience.Chain(resilience.CircuitBreaker(cfg))
+		calls := 0
+		for i := 0; i < 4; i++ {
+			require.ErrorIs(t, r.Run(ctx, failing(&calls)), errFailed)
+		}
+		time.Sleep(cfg.WaitDurationInOpenState)
+
+		var probes atomic.Int64
+		unblock := make(chan struct{})
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			go func() {
+				errs <- r.Run(ctx, func(ctx context.Context) error {
+					probes.Add(1)
+					<-unblock
+					return nil
+				})
+			}()
+		}
+
+		// The probes beyond the successes required to close the circuit are rejected while the others are in flight
+		for i := 0; i < 10-cfg.SuccessfulRequiredOnHalfOpen; i++ {
+			select {
+			case err := <-errs:
+				require.ErrorIs(t, err, resilience.ErrCircuitOpen)
+			case <-time.After(time.Second):
+				close(unblock)
+				t.Fatalf("%d probes were let through", probes.Load())
+			}
+		}
+		close(unblock)
+		for i := 0; i < cfg.SuccessfulRequiredOnHalfOpen; i++ {
+			require.NoError(t, <-errs)
+		}
+		require.Equal(t, int64(cfg.SuccessfulRequiredOnHalfOpen), probes.Load())
+		require.NoError(t, r.Run(ctx, succeeding))
+	})
+
+	t.Run("State Is Not Shared Between Runners", func(t *testing.T) {
+		f := resilience.NewFactory(resilience.CircuitBreaker(cfg))
+		calls := 0
+		for i := 0; i < 4; i++ {
+			require.ErrorIs(t, f.GetRunner("Client.GetUser").Run(ctx, failing(&calls)), errFailed)
+		}
+		require.ErrorIs(t, f.GetRunner("Client.GetUser").Run(ctx, succeeding), resilience.ErrCircuitOpen)
+		require.NoError(t, f.GetRunner("Client.SaveUser").Run(ctx, succeeding))
+	})
+}
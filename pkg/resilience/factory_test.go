@@ -0,0 +1,28 @@
+package resilience_test
+
+import (
+	"context"
+	"github.com/csueiras/reinforcer/pkg/resilience"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFactory_GetRunner(t *testing.T) {
+	mwCalled := 0
+	mwCreated := 0
+	f := resilience.NewFactory(
+		func(r resilience.Runner) resilience.Runner {
+			mwCreated++
+			return resilience.RunnerFunc(func(ctx context.Context, fn resilience.Func) error {
+				mwCalled++
+				return r.Run(ctx, fn)
+			})
+		},
+	)
+
+	require.NoError(t, f.GetRunner("Call1").Run(context.Background(), succeeding))
+	require.NoError(t, f.GetRunner("Call2").Run(context.Background(), succeeding))
+	require.NoError(t, f.GetRunner("Call1").Run(context.Background(), succeeding))
+	require.Equal(t, 3, mwCalled)
+	require.Equal(t, 2, mwCreated)
+}
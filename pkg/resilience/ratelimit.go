@@ -0,0 +1,98 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is the error returned when an execution exceeds the rate allowed by a rate limiter
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimitConfig is the configuration of the RateLimit middleware
+type RateLimitConfig struct {
+	// Rate is the number of executions allowed per second
+	Rate float64
+	// Burst is the number of executions allowed at once when the rate wasn't used, defaults to 1
+	Burst int
+	// MaxWaitTime is the maximum time an execution waits for the rate to allow it, when zero the executions exceeding the
+	// rate are rejected right away
+	MaxWaitTime time.Duration
+}
+
+// tokenBucket allows the executions at a steady rate with bursts
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token from the bucket returning the time to wait until the token is available, false is returned if
+// the token isn't available within the maximum wait time
+func (b *tokenBucket) reserve(now time.Time, maxWait time.Duration) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if wait > maxWait {
+		return 0, false
+	}
+	// The token is taken ahead of time so the executions waiting are given the tokens in order
+	b.tokens--
+	return wait, true
+}
+
+// cancel returns a reserved token that wasn't used
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+}
+
+// RateLimit creates a middleware that limits the rate of the executions, the executions exceeding the rate are rejected
+// with ErrRateLimited unless the rate allows them within the maximum wait time. This panics if the rate isn't positive.
+func RateLimit(cfg RateLimitConfig) Middleware {
+	if cfg.Rate <= 0 {
+		panic("rate limit must be positive")
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	return func(next Runner) Runner {
+		b := &tokenBucket{
+			rate:   cfg.Rate,
+			burst:  float64(cfg.Burst),
+			tokens: float64(cfg.Burst),
+			last:   time.Now(),
+		}
+		return RunnerFunc(func(ctx context.Context, fn Func) error {
+			wait, ok := b.reserve(time.Now(), cfg.MaxWaitTime)
+			if !ok {
+				return ErrRateLimited
+			}
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					b.cancel()
+					return ctx.Err()
+				}
+			}
+			return next.Run(ctx, fn)
+		})
+	}
+}
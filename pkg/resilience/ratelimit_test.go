@@ -0,0 +1,53 @@
+package resilience_test
+
+import (
+	"context"
+	"github.com/csueiras/reinforcer/pkg/resilience"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Burst", func(t *testing.T) {
+		r := resilience.Chain(resilience.RateLimit(resilience.RateLimitConfig{Rate: 1, Burst: 3}))
+		for i := 0; i < 3; i++ {
+			require.NoError(t, r.Run(ctx, succeeding))
+		}
+		require.ErrorIs(t, r.Run(ctx, succeeding), resilience.ErrRateLimited)
+	})
+
+	t.Run("Refills At Rate", func(t *testing.T) {
+		r := resilience.Chain(resilience.RateLimit(resilience.RateLimitConfig{Rate: 100}))
+		require.NoError(t, r.Run(ctx, succeeding))
+		require.ErrorIs(t, r.Run(ctx, succeeding), resilience.ErrRateLimited)
+		time.Sleep(15 * time.Millisecond)
+		require.NoError(t, r.Run(ctx, succeeding))
+	})
+
+	t.Run("Max Wait Time", func(t *testing.T) {
+		r := resilience.Chain(resilience.RateLimit(resilience.RateLimitConfig{Rate: 100, MaxWaitTime: 50 * time.Millisecond}))
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, r.Run(ctx, succeeding))
+		}
+		require.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+	})
+
+	t.Run("Context Done", func(t *testing.T) {
+		r := resilience.Chain(resilience.RateLimit(resilience.RateLimitConfig{Rate: 1, MaxWaitTime: time.Minute}))
+		require.NoError(t, r.Run(ctx, succeeding))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, r.Run(timeoutCtx, succeeding), context.DeadlineExceeded)
+	})
+
+	t.Run("Invalid Rate", func(t *testing.T) {
+		require.Panics(t, func() {
+			resilience.RateLimit(resilience.RateLimitConfig{})
+		})
+	})
+}
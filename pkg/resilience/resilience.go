@@ -0,0 +1,39 @@
+// Package resilience is reinforcer's own resilience runtime, it provides the runners targeted by the code generated with
+// the native runtime along with the middlewares implementing the common resiliency patterns: retries with backoff and
+// jitter, timeouts, circuit breakers, bulkheads and rate limiting.
+package resilience
+
+import "context"
+
+// Func is the function executed by a Runner
+type Func func(ctx context.Context) error
+
+// Runner executes a function with the resiliency constructs of its middlewares
+type Runner interface {
+	// Run executes the given function
+	Run(ctx context.Context, fn Func) error
+}
+
+// RunnerFunc is a function that implements Runner
+type RunnerFunc func(ctx context.Context, fn Func) error
+
+// Run executes the given function by calling the RunnerFunc
+func (r RunnerFunc) Run(ctx context.Context, fn Func) error {
+	return r(ctx, fn)
+}
+
+// Middleware wraps a Runner with a resiliency construct, the middleware is called once per runner so that any state it
+// keeps (e.g. the state of a circuit breaker) isn't shared between runners
+type Middleware func(next Runner) Runner
+
+// Chain creates a Runner from the given middlewares, the first middleware is the outermost one (i.e. the first one
+// executed)
+func Chain(middlewares ...Middleware) Runner {
+	var r Runner = RunnerFunc(func(ctx context.Context, fn Func) error {
+		return fn(ctx)
+	})
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		r = middlewares[i](r)
+	}
+	return r
+}
@@ -0,0 +1,46 @@
+package resilience_test
+
+import (
+	"context"
+	"errors"
+	"github.com/csueiras/reinforcer/pkg/resilience"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var errFailed = errors.New("failed")
+
+// failing is a function that always fails counting its calls
+func failing(calls *int) resilience.Func {
+	return func(ctx context.Context) error {
+		*calls++
+		return errFailed
+	}
+}
+
+func succeeding(_ context.Context) error {
+	return nil
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	named := func(name string) resilience.Middleware {
+		return func(next resilience.Runner) resilience.Runner {
+			return resilience.RunnerFunc(func(ctx context.Context, fn resilience.Func) error {
+				order = append(order, name)
+				return next.Run(ctx, fn)
+			})
+		}
+	}
+
+	r := resilience.Chain(named("outer"), named("inner"))
+	require.NoError(t, r.Run(context.Background(), func(ctx context.Context) error {
+		order = append(order, "fn")
+		return nil
+	}))
+	require.Equal(t, []string{"outer", "inner", "fn"}, order)
+
+	calls := 0
+	require.ErrorIs(t, resilience.Chain().Run(context.Background(), failing(&calls)), errFailed)
+	require.Equal(t, 1, calls)
+}
@@ -0,0 +1,88 @@
+package resilience
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultRetryTimes    = 3
+	defaultRetryWaitBase = 20 * time.Millisecond
+)
+
+// RetryConfig is the configuration of the Retry middleware
+type RetryConfig struct {
+	// Times is the number of retries after the first attempt, defaults to 3
+	Times int
+	// WaitBase is the wait before the first retry, it doubles on every retry unless the backoff is disabled. Defaults to
+	// 20ms
+	WaitBase time.Duration
+	// MaxWait caps the wait between attempts, the wait is unbounded when zero
+	MaxWait time.Duration
+	// DisableBackoff waits WaitBase before every retry
+	DisableBackoff bool
+	// Jitter is the fraction of each wait, between 0 and 1, that is randomized to spread the retries of concurrent
+	// callers (e.g. with 0.2 each wait lasts between 80% and 100% of its duration)
+	Jitter float64
+}
+
+func (c *RetryConfig) defaults() {
+	if c.Times <= 0 {
+		c.Times = defaultRetryTimes
+	}
+	if c.WaitBase <= 0 {
+		c.WaitBase = defaultRetryWaitBase
+	}
+	if c.Jitter < 0 {
+		c.Jitter = 0
+	}
+	if c.Jitter > 1 {
+		c.Jitter = 1
+	}
+}
+
+// wait is the duration to wait before the given retry, starting at zero
+func (c *RetryConfig) wait(retry int) time.Duration {
+	maxWait := c.MaxWait
+	if maxWait <= 0 {
+		// Stop doubling the wait before it overflows
+		maxWait = math.MaxInt64 / 2
+	}
+	wait := c.WaitBase
+	if !c.DisableBackoff {
+		for i := 0; i < retry && wait < maxWait; i++ {
+			wait *= 2
+		}
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	if c.Jitter > 0 {
+		wait -= time.Duration(rand.Float64() * c.Jitter * float64(wait))
+	}
+	return wait
+}
+
+// Retry creates a middleware that retries the failed executions, the retries stop early when the context is done and
+// the error of the last attempt is returned
+func Retry(cfg RetryConfig) Middleware {
+	cfg.defaults()
+	return func(next Runner) Runner {
+		return RunnerFunc(func(ctx context.Context, fn Func) error {
+			err := next.Run(ctx, fn)
+			for retry := 0; err != nil && retry < cfg.Times; retry++ {
+				timer := time.NewTimer(cfg.wait(retry))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				case <-timer.C:
+				}
+				err = next.Run(ctx, fn)
+			}
+			return err
+		})
+	}
+}
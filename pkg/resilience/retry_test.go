@@ -0,0 +1,82 @@
+package resilience_test
+
+import (
+	"context"
+	"github.com/csueiras/reinforcer/pkg/resilience"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// waits runs the function with the given retry configuration, returning the waits between its attempts
+func waits(t *testing.T, cfg resilience.RetryConfig) []time.Duration {
+	var attempts []time.Time
+	err := resilience.Chain(resilience.Retry(cfg)).Run(context.Background(), func(ctx context.Context) error {
+		attempts = append(attempts, time.Now())
+		return errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	var got []time.Duration
+	for i := 1; i < len(attempts); i++ {
+		got = append(got, attempts[i].Sub(attempts[i-1]))
+	}
+	return got
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("Default Retries", func(t *testing.T) {
+		require.Len(t, waits(t, resilience.RetryConfig{WaitBase: time.Millisecond}), 3)
+	})
+
+	t.Run("Stops On Success", func(t *testing.T) {
+		calls := 0
+		err := resilience.Chain(resilience.Retry(resilience.RetryConfig{Times: 5, WaitBase: time.Millisecond})).Run(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return errFailed
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		got := waits(t, resilience.RetryConfig{Times: 3, WaitBase: 5 * time.Millisecond})
+		require.Len(t, got, 3)
+		for i, want := range []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond} {
+			require.GreaterOrEqual(t, got[i], want)
+		}
+	})
+
+	t.Run("Max Wait", func(t *testing.T) {
+		got := waits(t, resilience.RetryConfig{Times: 4, WaitBase: 5 * time.Millisecond, MaxWait: 10 * time.Millisecond})
+		require.Len(t, got, 4)
+		require.Less(t, got[3], 40*time.Millisecond)
+	})
+
+	t.Run("Disable Backoff", func(t *testing.T) {
+		got := waits(t, resilience.RetryConfig{Times: 3, WaitBase: 5 * time.Millisecond, DisableBackoff: true})
+		require.Len(t, got, 3)
+		require.Less(t, got[2], 20*time.Millisecond)
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		got := waits(t, resilience.RetryConfig{Times: 10, WaitBase: 10 * time.Millisecond, DisableBackoff: true, Jitter: 1})
+		var total time.Duration
+		for _, wait := range got {
+			total += wait
+		}
+		require.Less(t, total, 100*time.Millisecond, "the waits should be shortened by the jitter")
+	})
+
+	t.Run("Context Done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		calls := 0
+		err := resilience.Chain(resilience.Retry(resilience.RetryConfig{Times: 3, WaitBase: time.Minute})).Run(ctx, failing(&calls))
+		require.ErrorIs(t, err, errFailed)
+		require.Equal(t, 1, calls)
+	})
+}
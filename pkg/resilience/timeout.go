@@ -0,0 +1,60 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultTimeout = time.Second
+
+// ErrTimeout is the error returned when an execution doesn't finish within its timeout
+var ErrTimeout = errors.New("timeout")
+
+// Timeout creates a middleware that cancels the context of the executions that don't finish within the given timeout,
+// the execution returns ErrTimeout without waiting for the function to honor the cancellation. Defaults to 1s. As with
+// goresilience's timeout, the context of a successful execution isn't cancelled when it returns so that the results
+// bound to it (e.g. a stream or the body of a response) remain usable by the caller.
+func Timeout(timeout time.Duration) Middleware {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return func(next Runner) Runner {
+		return RunnerFunc(func(ctx context.Context, fn Func) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			succeeded := false
+			defer func() {
+				// The context of a successful execution is released by its deadline
+				if !succeeded {
+					cancel()
+				}
+			}()
+
+			// The results are buffered so the execution doesn't leak when it outlives the timeout, a panic is propagated
+			// to the caller as it can't be recovered from the execution's goroutine
+			result := make(chan error, 1)
+			panics := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panics <- p
+					}
+				}()
+				result <- next.Run(ctx, fn)
+			}()
+
+			select {
+			case err := <-result:
+				succeeded = err == nil
+				return err
+			case p := <-panics:
+				panic(p)
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return ErrTimeout
+				}
+				return ctx.Err()
+			}
+		})
+	}
+}
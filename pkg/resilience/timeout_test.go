@@ -0,0 +1,95 @@
+package resilience_test
+
+import (
+	"context"
+	"github.com/csueiras/reinforcer/pkg/resilience"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	r := resilience.Chain(resilience.Timeout(10 * time.Millisecond))
+
+	t.Run("Finishes In Time", func(t *testing.T) {
+		calls := 0
+		require.ErrorIs(t, r.Run(context.Background(), failing(&calls)), errFailed)
+		require.NoError(t, r.Run(context.Background(), succeeding))
+	})
+
+	t.Run("Times Out", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		err := r.Run(context.Background(), func(ctx context.Context) error {
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		})
+		require.ErrorIs(t, err, resilience.ErrTimeout)
+		<-cancelled
+	})
+
+	t.Run("Doesn't Wait For The Function", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+		err := r.Run(context.Background(), func(ctx context.Context) error {
+			<-unblock
+			return nil
+		})
+		require.ErrorIs(t, err, resilience.ErrTimeout)
+	})
+
+	t.Run("Parent Context Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := r.Run(ctx, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Results Bound To The Context", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The rest of the body is sent once the execution returned
+			_, _ = w.Write([]byte("stream"))
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+			_, _ = w.Write([]byte("ed"))
+		}))
+		defer ts.Close()
+
+		r := resilience.Chain(resilience.Timeout(time.Second))
+		var resp *http.Response
+		require.NoError(t, r.Run(context.Background(), func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+			if err != nil {
+				return err
+			}
+			resp, err = ts.Client().Do(req)
+			return err
+		}))
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "streamed", string(b))
+
+		// The context of a failed execution is cancelled right away
+		var failedCtx context.Context
+		require.ErrorIs(t, r.Run(context.Background(), func(ctx context.Context) error {
+			failedCtx = ctx
+			return errFailed
+		}), errFailed)
+		require.ErrorIs(t, failedCtx.Err(), context.Canceled)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		require.PanicsWithValue(t, "boom", func() {
+			_ = r.Run(context.Background(), func(ctx context.Context) error {
+				panic("boom")
+			})
+		})
+	})
+}
@@ -0,0 +1,58 @@
+// Package adapters exposes the constructs of other resilience libraries as goresilience middlewares, so these can be
+// used by the runners of the reinforced proxies. The libraries are adapted through the methods of their types, so this
+// package doesn't depend on them.
+package adapters
+
+import (
+	"context"
+	"github.com/slok/goresilience"
+	"time"
+)
+
+// Stop is the duration returned by a BackOff when no more retries must be done, it matches backoff.Stop of
+// github.com/cenkalti/backoff
+const Stop time.Duration = -1
+
+// BackOff is the policy that determines the wait between retries, it is satisfied by the BackOff of
+// github.com/cenkalti/backoff (e.g. *backoff.ExponentialBackOff)
+type BackOff interface {
+	// NextBackOff returns the duration to wait before the next retry, or Stop when no more retries must be done
+	NextBackOff() time.Duration
+	// Reset restores the policy to its initial state
+	Reset()
+}
+
+// Backoff creates a middleware that retries the failed executions waiting as dictated by a BackOff, the back-offs are
+// stateful so a new one is created for every execution:
+//
+//	adapters.Backoff(func() adapters.BackOff {
+//	    return backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 3)
+//	})
+//
+// The retries stop early when the context is done, the error of the last attempt is returned.
+func Backoff(newBackOff func() BackOff) goresilience.Middleware {
+	return func(next goresilience.Runner) goresilience.Runner {
+		return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+			b := newBackOff()
+			b.Reset()
+			for {
+				err := next.Run(ctx, f)
+				if err == nil {
+					return nil
+				}
+
+				wait := b.NextBackOff()
+				if wait == Stop {
+					return err
+				}
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				case <-timer.C:
+				}
+			}
+		})
+	}
+}
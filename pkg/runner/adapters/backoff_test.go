@@ -0,0 +1,90 @@
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/csueiras/reinforcer/pkg/runner/adapters"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var errFailed = errors.New("failed")
+
+// fakeBackOff is a BackOff that waits the given durations, mimicking the constant and exponential back-offs of
+// github.com/cenkalti/backoff
+type fakeBackOff struct {
+	waits  []time.Duration
+	next   int
+	resets int
+}
+
+func (b *fakeBackOff) NextBackOff() time.Duration {
+	if b.next >= len(b.waits) {
+		return adapters.Stop
+	}
+	b.next++
+	return b.waits[b.next-1]
+}
+
+func (b *fakeBackOff) Reset() {
+	b.next = 0
+	b.resets++
+}
+
+func TestBackoff(t *testing.T) {
+	var backOffs []*fakeBackOff
+	f := runner.NewFactory(adapters.Backoff(func() adapters.BackOff {
+		b := &fakeBackOff{waits: []time.Duration{time.Millisecond, 2 * time.Millisecond}}
+		backOffs = append(backOffs, b)
+		return b
+	}))
+	r := f.GetRunner("Client.GetUser")
+
+	t.Run("Retries Until Stop", func(t *testing.T) {
+		calls := 0
+		err := r.Run(context.Background(), func(ctx context.Context) error {
+			calls++
+			return errFailed
+		})
+		require.ErrorIs(t, err, errFailed)
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("Stops On Success", func(t *testing.T) {
+		calls := 0
+		err := r.Run(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls == 1 {
+				return errFailed
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("New Back-off Per Execution", func(t *testing.T) {
+		require.Len(t, backOffs, 2)
+		for _, b := range backOffs {
+			require.Equal(t, 1, b.resets)
+		}
+	})
+
+	t.Run("Context Done", func(t *testing.T) {
+		f := runner.NewFactory(adapters.Backoff(func() adapters.BackOff {
+			return &fakeBackOff{waits: []time.Duration{time.Minute}}
+		}))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		calls := 0
+		err := f.GetRunner("Client.GetUser").Run(ctx, func(ctx context.Context) error {
+			calls++
+			return errFailed
+		})
+		require.ErrorIs(t, err, errFailed)
+		require.Equal(t, 1, calls)
+	})
+}
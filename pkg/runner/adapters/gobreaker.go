@@ -0,0 +1,32 @@
+package adapters
+
+import (
+	"context"
+	"github.com/slok/goresilience"
+)
+
+// CircuitBreaker is a circuit breaker that executes the requests while its circuit is closed, it is satisfied by the
+// CircuitBreaker of github.com/sony/gobreaker (and by CircuitBreaker[any] of its generic version)
+type CircuitBreaker interface {
+	// Execute runs the given request if the circuit breaker accepts it, otherwise the rejection error is returned
+	Execute(req func() (interface{}, error)) (interface{}, error)
+}
+
+// GoBreaker creates a middleware that runs the executions through an existing circuit breaker. The circuit breaker's
+// state is shared by all the runners given this middleware, a breaker per runner can be used by routing the runner
+// names to their breakers:
+//
+//	runner.NewRoutingFactory(
+//	    runner.Route(reinforced.ClientMethods.GetUser, adapters.GoBreaker(getUserBreaker)),
+//	    runner.Route(reinforced.ClientMethods.SaveUser, adapters.GoBreaker(saveUserBreaker)),
+//	)
+func GoBreaker(cb CircuitBreaker) goresilience.Middleware {
+	return func(next goresilience.Runner) goresilience.Runner {
+		return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+			_, err := cb.Execute(func() (interface{}, error) {
+				return nil, next.Run(ctx, f)
+			})
+			return err
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/csueiras/reinforcer/pkg/runner/adapters"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var errOpenState = errors.New("circuit breaker is open")
+
+// fakeBreaker is a CircuitBreaker that opens after the given number of consecutive failures, mimicking the default
+// settings of github.com/sony/gobreaker
+type fakeBreaker struct {
+	maxFailures int
+	failures    int
+}
+
+func (b *fakeBreaker) Execute(req func() (interface{}, error)) (interface{}, error) {
+	if b.failures >= b.maxFailures {
+		return nil, errOpenState
+	}
+	res, err := req()
+	if err != nil {
+		b.failures++
+		return nil, err
+	}
+	b.failures = 0
+	return res, nil
+}
+
+func TestGoBreaker(t *testing.T) {
+	failing := func(calls *int) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			*calls++
+			return errFailed
+		}
+	}
+
+	t.Run("Opens The Breaker", func(t *testing.T) {
+		r := runner.NewFactory(adapters.GoBreaker(&fakeBreaker{maxFailures: 2})).GetRunner("Client.GetUser")
+		calls := 0
+		require.ErrorIs(t, r.Run(context.Background(), failing(&calls)), errFailed)
+		require.ErrorIs(t, r.Run(context.Background(), failing(&calls)), errFailed)
+		require.ErrorIs(t, r.Run(context.Background(), failing(&calls)), errOpenState)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Existing Breakers Per Runner", func(t *testing.T) {
+		getUser := &fakeBreaker{maxFailures: 1}
+		saveUser := &fakeBreaker{maxFailures: 1}
+		f := runner.NewRoutingFactory(
+			runner.Route("Client.GetUser", adapters.GoBreaker(getUser)),
+			runner.Route("Client.SaveUser", adapters.GoBreaker(saveUser)),
+		)
+
+		calls := 0
+		require.ErrorIs(t, f.GetRunner("Client.GetUser").Run(context.Background(), failing(&calls)), errFailed)
+		require.ErrorIs(t, f.GetRunner("Client.GetUser").Run(context.Background(), failing(&calls)), errOpenState)
+		require.NoError(t, f.GetRunner("Client.SaveUser").Run(context.Background(), func(ctx context.Context) error {
+			return nil
+		}))
+		require.Equal(t, 1, getUser.failures)
+		require.Equal(t, 0, saveUser.failures)
+	})
+}
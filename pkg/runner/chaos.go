@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"github.com/slok/goresilience"
+	"math/rand"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ChaosEnv is the environment variable that enables the fault injection of a Chaos when it's created, its value is
+// parsed by strconv.ParseBool (e.g. REINFORCER_CHAOS=true)
+const ChaosEnv = "REINFORCER_CHAOS"
+
+var (
+	// ErrChaos is the error of the attempts failed by a fault that doesn't give its own error
+	ErrChaos = errors.New("chaos: injected error")
+	// ErrChaosOutage is the error of the attempts failed by an outage of the Chaos
+	ErrChaosOutage = errors.New("chaos: injected outage")
+	// ErrChaosPanic is the value the attempts panic with when a fault injects a panic
+	ErrChaosPanic = errors.New("chaos: injected panic")
+)
+
+// ChaosConfig describes the faults injected by the Chaos
+type ChaosConfig struct {
+	// Fault is the fault injected into the attempts of the targeted runners
+	Fault
+	// Methods are the runners targeted by the faults, either exact runner names (e.g. "Client.GetUser") or globs as
+	// supported by path.Match (e.g. "Client.*"). The patterns are matched against the runner's name with and without
+	// its instance name. Every runner is targeted when empty.
+	Methods []string
+}
+
+// Chaos is a FaultSource injecting faults into the attempts of the runners while enabled. It's meant for testing the
+// resilience of the services in environments such as staging, the faults are toggled at runtime with Enable and Disable,
+// or on start with the ChaosEnv environment variable.
+//
+// The faults are injected into the runners of any factory with Wrap, these remain in place when the factory's policies
+// are updated:
+//
+//	r := runner.Wrap(runner.FromConfig(...), chaos.Middleware)
+type Chaos struct {
+	enabled     atomic.Bool
+	config      atomic.Pointer[ChaosConfig]
+	outageUntil atomic.Int64
+}
+
+// NewChaos creates a Chaos injecting the faults of the given configuration, it's enabled if the ChaosEnv environment
+// variable is true. NewChaos panics if any of the targeted methods is a malformed pattern.
+func NewChaos(cfg ChaosConfig) *Chaos {
+	c := &Chaos{}
+	c.Configure(cfg)
+	if enabled, err := strconv.ParseBool(os.Getenv(ChaosEnv)); err == nil {
+		c.enabled.Store(enabled)
+	}
+	return c
+}
+
+// Enable starts injecting the faults. This is thread-safe.
+func (c *Chaos) Enable() {
+	c.enabled.Store(true)
+}
+
+// Disable stops injecting the faults, including the outage in progress. This is thread-safe.
+func (c *Chaos) Disable() {
+	c.enabled.Store(false)
+}
+
+// Enabled determines whether the faults are being injected. This is thread-safe.
+func (c *Chaos) Enabled() bool {
+	return c.enabled.Load()
+}
+
+// Configure replaces the faults injected, Configure panics if any of the targeted methods is a malformed pattern. This is
+// thread-safe.
+func (c *Chaos) Configure(cfg ChaosConfig) {
+	for _, pattern := range cfg.Methods {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("invalid chaos method pattern %q: %v", pattern, err))
+		}
+	}
+	cfg.Methods = append([]string(nil), cfg.Methods...)
+	c.config.Store(&cfg)
+}
+
+// Outage fails all the attempts of the targeted runners with ErrChaosOutage for the given duration, starting now. The
+// outage only affects the attempts while the Chaos is enabled. This is thread-safe.
+func (c *Chaos) Outage(d time.Duration) {
+	c.outageUntil.Store(time.Now().Add(d).UnixNano())
+}
+
+// Middleware creates the middleware that injects the faults into the runner with the given name, it's a NamedMiddleware
+// to be given to Wrap or NewNamedFactory
+func (c *Chaos) Middleware(name string) goresilience.Middleware {
+	return FaultMiddleware(name, c)
+}
+
+// Fault returns the fault injected into the next attempt of the runner with the given name. This is thread-safe.
+func (c *Chaos) Fault(name string) (Fault, bool) {
+	if !c.enabled.Load() {
+		return Fault{}, false
+	}
+	cfg := c.config.Load()
+	if !cfg.targets(name) {
+		return Fault{}, false
+	}
+	if time.Now().UnixNano() < c.outageUntil.Load() {
+		return Fault{ErrorRate: 1, Err: ErrChaosOutage}, true
+	}
+	return cfg.Fault, true
+}
+
+// Float64 returns the next random number of the runner with the given name, all the runners share the same source. This
+// is thread-safe.
+func (c *Chaos) Float64(_ string) float64 {
+	return rand.Float64()
+}
+
+// targets determines whether the runner with the given name is targeted by the faults
+func (cfg *ChaosConfig) targets(name string) bool {
+	if len(cfg.Methods) == 0 {
+		return true
+	}
+	// The names are qualified as "Type.Method" optionally prefixed by the instance's name
+	unqualified := name
+	if segments := strings.Split(name, "."); len(segments) > 2 {
+		unqualified = strings.Join(segments[len(segments)-2:], ".")
+	}
+	for _, pattern := range cfg.Methods {
+		// The patterns were validated so no error can be returned
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, unqualified); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package runner_test
+
+import (
+	"context"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience"
+	"github.com/slok/goresilience/retry"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+// run executes a call that succeeds in the runner with the given name
+func run(f runner.RunnerFactory, name string) error {
+	return f.GetRunner(name).Run(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+}
+
+func TestChaos(t *testing.T) {
+	failing := runner.ChaosConfig{Fault: runner.Fault{ErrorRate: 1}}
+
+	t.Run("Disabled By Default", func(t *testing.T) {
+		chaos := runner.NewChaos(failing)
+		require.False(t, chaos.Enabled())
+		require.NoError(t, run(runner.Wrap(runner.NewFactory(), chaos.Middleware), "Client.GetUser"))
+	})
+
+	t.Run("Enabled By Environment", func(t *testing.T) {
+		t.Setenv(runner.ChaosEnv, "true")
+		chaos := runner.NewChaos(failing)
+		require.True(t, chaos.Enabled())
+		require.ErrorIs(t, run(runner.Wrap(runner.NewFactory(), chaos.Middleware), "Client.GetUser"), runner.ErrChaos)
+	})
+
+	t.Run("Toggled", func(t *testing.T) {
+		chaos := runner.NewChaos(failing)
+		f := runner.Wrap(runner.NewFactory(), chaos.Middleware)
+
+		chaos.Enable()
+		require.ErrorIs(t, run(f, "Client.GetUser"), runner.ErrChaos)
+		chaos.Disable()
+		require.NoError(t, run(f, "Client.GetUser"))
+	})
+
+	t.Run("Error Rate", func(t *testing.T) {
+		chaos := runner.NewChaos(runner.ChaosConfig{Fault: runner.Fault{ErrorRate: 0.5}})
+		chaos.Enable()
+		f := runner.Wrap(runner.NewFactory(), chaos.Middleware)
+
+		failures := 0
+		for i := 0; i < 1000; i++ {
+			if run(f, "Client.GetUser") != nil {
+				failures++
+			}
+		}
+		require.InDelta(t, 500, failures, 100)
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		chaos := runner.NewChaos(runner.ChaosConfig{Fault: runner.Fault{Latency: 20 * time.Millisecond}})
+		chaos.Enable()
+		f := runner.Wrap(runner.NewFactory(), chaos.Middleware)
+
+		start := time.Now()
+		require.NoError(t, run(f, "Client.GetUser"))
+		require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, f.GetRunner("Client.GetUser").Run(ctx, func(ctx context.Context) error {
+			return nil
+		}), context.DeadlineExceeded)
+	})
+
+	t.Run("Outage", func(t *testing.T) {
+		chaos := runner.NewChaos(runner.ChaosConfig{})
+		chaos.Enable()
+		f := runner.Wrap(runner.NewFactory(), chaos.Middleware)
+
+		chaos.Outage(50 * time.Millisecond)
+		require.ErrorIs(t, run(f, "Client.GetUser"), runner.ErrChaosOutage)
+
+		chaos.Disable()
+		require.NoError(t, run(f, "Client.GetUser"), "outages only apply while enabled")
+		chaos.Enable()
+
+		require.Eventually(t, func() bool {
+			return run(f, "Client.GetUser") == nil
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Targeted Methods", func(t *testing.T) {
+		chaos := runner.NewChaos(runner.ChaosConfig{Fault: runner.Fault{ErrorRate: 1}, Methods: []string{"Client.GetUser", "Service.*"}})
+		chaos.Enable()
+		f := runner.Wrap(runner.NewFactory(), chaos.Middleware)
+
+		require.ErrorIs(t, run(f, "Client.GetUser"), runner.ErrChaos)
+		require.ErrorIs(t, run(f, "payments.Client.GetUser"), runner.ErrChaos)
+		require.ErrorIs(t, run(f, "Service.GetData"), runner.ErrChaos)
+		require.NoError(t, run(f, "Client.SaveUser"))
+
+		chaos.Configure(runner.ChaosConfig{Fault: runner.Fault{ErrorRate: 1}, Methods: []string{"payments.*"}})
+		require.ErrorIs(t, run(f, "payments.Client.SaveUser"), runner.ErrChaos)
+		require.NoError(t, run(f, "Client.SaveUser"))
+	})
+
+	t.Run("Retried", func(t *testing.T) {
+		chaos := runner.NewChaos(runner.ChaosConfig{})
+		chaos.Enable()
+		var attempts int
+		counted := func(next goresilience.Runner) goresilience.Runner {
+			return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+				return next.Run(ctx, func(ctx context.Context) error {
+					attempts++
+					return f(ctx)
+				})
+			})
+		}
+		f := runner.Wrap(runner.NewFactory(retry.NewMiddleware(retry.Config{Times: 1, WaitBase: time.Millisecond}), counted), chaos.Middleware)
+
+		chaos.Outage(time.Minute)
+		calls := 0
+		err := f.GetRunner("Client.GetUser").Run(context.Background(), func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+		require.ErrorIs(t, err, runner.ErrChaosOutage)
+		require.Equal(t, 2, attempts, "the faults are injected into every attempt")
+		require.Zero(t, calls)
+	})
+
+	t.Run("Routing Factory", func(t *testing.T) {
+		chaos := runner.NewChaos(runner.ChaosConfig{Fault: runner.Fault{ErrorRate: 1}, Methods: []string{"Client.GetUser"}})
+		chaos.Enable()
+		f := runner.Wrap(runner.NewRoutingFactory(runner.Route("Client.*", retry.NewMiddleware(retry.Config{Times: 1, WaitBase: time.Millisecond}))), chaos.Middleware)
+
+		require.ErrorIs(t, run(f, "Client.GetUser"), runner.ErrChaos)
+		require.NoError(t, run(f, "Client.SaveUser"))
+	})
+
+	t.Run("Kept Across Updates", func(t *testing.T) {
+		chaos := runner.NewChaos(failing)
+		chaos.Enable()
+		factory, err := runner.FromConfig(strings.NewReader("default:\n  timeout: 1s\n"))
+		require.NoError(t, err)
+		f := runner.Wrap(factory, chaos.Middleware)
+		r := f.GetRunner("Client.GetUser")
+		require.ErrorIs(t, run(f, "Client.GetUser"), runner.ErrChaos)
+
+		factory.Update(mustParseConfig(t, "default:\n  retry:\n    times: 1\n    waitBase: 1ms\n"))
+		require.ErrorIs(t, run(f, "Client.GetUser"), runner.ErrChaos)
+		require.ErrorIs(t, r.Run(context.Background(), func(ctx context.Context) error {
+			return nil
+		}), runner.ErrChaos)
+	})
+
+	t.Run("Malformed Pattern", func(t *testing.T) {
+		require.Panics(t, func() {
+			runner.NewChaos(runner.ChaosConfig{Methods: []string{"["}})
+		})
+	})
+}
@@ -0,0 +1,446 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"github.com/slok/goresilience"
+	"github.com/slok/goresilience/bulkhead"
+	"github.com/slok/goresilience/circuitbreaker"
+	"github.com/slok/goresilience/concurrencylimit"
+	"github.com/slok/goresilience/concurrencylimit/execute"
+	"github.com/slok/goresilience/concurrencylimit/limit"
+	"github.com/slok/goresilience/retry"
+	"github.com/slok/goresilience/timeout"
+	"gopkg.in/yaml.v2"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Limiters supported by the concurrency limit policy
+const (
+	StaticLimiter = "static"
+	AIMDLimiter   = "aimd"
+)
+
+// Config is the declarative configuration of the policies applied to the runners, the policies are given per type and
+// per method of the generated proxies, e.g.:
+//
+//	default:
+//	  timeout: 1s
+//	types:
+//	  Client:
+//	    default:
+//	      retry:
+//	        times: 3
+//	    methods:
+//	      SaveUser:
+//	        timeout: 100ms
+//
+// The most specific policy is used for each runner, in order: the method's policy, the type's default policy and lastly
+// the default policy. Policies aren't merged, the runners that aren't matched by any policy don't use any middlewares.
+type Config struct {
+	Default *Policy
+	Types   map[string]*TypeConfig
+}
+
+// TypeConfig is the configuration of the policies applied to the runners of a type
+type TypeConfig struct {
+	Default *Policy
+	Methods map[string]*Policy
+}
+
+// Policy describes the middlewares of a runner, these are chained in the order: circuit breaker, concurrency limit,
+// bulkhead, retry and timeout, thus the timeout applies to each attempt.
+type Policy struct {
+	Timeout          time.Duration
+	Retry            *RetryPolicy
+	CircuitBreaker   *CircuitBreakerPolicy
+	Bulkhead         *BulkheadPolicy
+	ConcurrencyLimit *ConcurrencyLimitPolicy
+}
+
+// RetryPolicy configures the retry middleware
+type RetryPolicy struct {
+	Times          int
+	WaitBase       time.Duration
+	DisableBackoff bool
+}
+
+// CircuitBreakerPolicy configures the circuit breaker middleware
+type CircuitBreakerPolicy struct {
+	ErrorPercentThresholdToOpen        int
+	MinimumRequestToOpen               int
+	SuccessfulRequiredOnHalfOpen       int
+	WaitDurationInOpenState            time.Duration
+	MetricsSlidingWindowBucketQuantity int
+	MetricsBucketDuration              time.Duration
+}
+
+// BulkheadPolicy configures the bulkhead middleware
+type BulkheadPolicy struct {
+	Workers     int
+	MaxWaitTime time.Duration
+}
+
+// ConcurrencyLimitPolicy configures the concurrency limit middleware, the limit is the fixed limit of the static limiter
+// or the minimum limit of the AIMD limiter
+type ConcurrencyLimitPolicy struct {
+	Limiter     string
+	Limit       int
+	MaxWaitTime time.Duration
+}
+
+// ConfigError is an error found in the configuration at the given path, e.g. "types.Client.methods.GetUser.retry.times"
+type ConfigError struct {
+	Path    string
+	Message string
+}
+
+// Error satisfies the error interface
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// FromConfig creates an instance of a Runner factory with the policies read from the given YAML or JSON configuration,
+// see Config for its format.
+func FromConfig(r io.Reader) (*Factory, error) {
+	cfg, err := ParseConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewConfigFactory(cfg), nil
+}
+
+// NewConfigFactory creates an instance of a Runner factory with the policies of the given configuration. Each runner
+// gets its own instances of the middlewares so that their state (e.g. the circuit breaker's) isn't shared.
+func NewConfigFactory(cfg *Config) *Factory {
+	return newFactory(cfg.newChain)
+}
+
+// ParseConfig reads the YAML or JSON configuration from the given reader, all the errors found in the configuration are
+// reported as a ConfigError.
+func ParseConfig(r io.Reader) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config; error=%w", err)
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config; error=%w", err)
+	}
+
+	d := &decoder{}
+	cfg := d.config(raw)
+	if len(d.errs) > 0 {
+		return nil, errors.Join(d.errs...)
+	}
+	return cfg, nil
+}
+
+// policy finds the policy for the runner with the given name, the names are qualified as "Type.Method" optionally
+// prefixed by the instance's name. The runners that aren't matched by any policy are given an empty policy.
+func (c *Config) policy(name string) *Policy {
+	segments := strings.Split(name, ".")
+	if len(segments) >= 2 {
+		typeName, methodName := segments[len(segments)-2], segments[len(segments)-1]
+		if t, ok := c.Types[typeName]; ok {
+			if p, ok := t.Methods[methodName]; ok {
+				return p
+			}
+			if t.Default != nil {
+				return t.Default
+			}
+		}
+	}
+	if c.Default != nil {
+		return c.Default
+	}
+	return &Policy{}
+}
+
+// newChain creates the chain of the runner with the given name from its policy
+func (c *Config) newChain(name string) *chain {
+	p := c.policy(name)
+	middlewares, release := p.middlewares()
+	ch := newChain(middlewares)
+	ch.policy = p
+	ch.release = release
+	return ch
+}
+
+// middlewares creates the middlewares described by this policy, along with the function that releases the resources
+// held by them (e.g. the workers of the bulkhead) once they are no longer used
+func (p *Policy) middlewares() ([]goresilience.Middleware, func()) {
+	var middlewares []goresilience.Middleware
+	var releases []func()
+	if cb := p.CircuitBreaker; cb != nil {
+		middlewares = append(middlewares, circuitbreaker.NewMiddleware(circuitbreaker.Config{
+			ErrorPercentThresholdToOpen:        cb.ErrorPercentThresholdToOpen,
+			MinimumRequestToOpen:               cb.MinimumRequestToOpen,
+			SuccessfulRequiredOnHalfOpen:       cb.SuccessfulRequiredOnHalfOpen,
+			WaitDurationInOpenState:            cb.WaitDurationInOpenState,
+			MetricsSlidingWindowBucketQuantity: cb.MetricsSlidingWindowBucketQuantity,
+			MetricsBucketDuration:              cb.MetricsBucketDuration,
+		}))
+	}
+	if cl := p.ConcurrencyLimit; cl != nil {
+		var limiter limit.Limiter
+		if cl.Limiter == StaticLimiter {
+			limiter = limit.NewStatic(cl.Limit)
+		} else {
+			limiter = limit.NewAIMD(limit.AIMDConfig{MinimumLimit: cl.Limit})
+		}
+		executor := execute.NewFIFO(execute.FIFOConfig{MaxWaitTime: cl.MaxWaitTime})
+		middlewares = append(middlewares, concurrencylimit.NewMiddleware(concurrencylimit.Config{
+			Limiter:  limiter,
+			Executor: executor,
+		}))
+		releases = append(releases, func() {
+			executor.SetWorkerQuantity(0)
+		})
+	}
+	if bh := p.Bulkhead; bh != nil {
+		stopC := make(chan struct{})
+		middlewares = append(middlewares, bulkhead.NewMiddleware(bulkhead.Config{
+			Workers:     bh.Workers,
+			MaxWaitTime: bh.MaxWaitTime,
+			StopC:       stopC,
+		}))
+		releases = append(releases, func() {
+			close(stopC)
+		})
+	}
+	if rt := p.Retry; rt != nil {
+		middlewares = append(middlewares, retry.NewMiddleware(retry.Config{
+			Times:          rt.Times,
+			WaitBase:       rt.WaitBase,
+			DisableBackoff: rt.DisableBackoff,
+		}))
+	}
+	if p.Timeout > 0 {
+		middlewares = append(middlewares, timeout.NewMiddleware(timeout.Config{Timeout: p.Timeout}))
+	}
+	return middlewares, func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+}
+
+// decoder decodes the configuration from the generic representation of the YAML/JSON document, it keeps track of the
+// errors found along with their path in the document
+type decoder struct {
+	errs []error
+}
+
+func (d *decoder) errorf(path string, format string, args ...interface{}) {
+	if path == "" {
+		path = "(root)"
+	}
+	d.errs = append(d.errs, &ConfigError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *decoder) config(v interface{}) *Config {
+	cfg := &Config{}
+	d.fields("", v, map[string]func(string, interface{}){
+		"default": func(path string, v interface{}) {
+			cfg.Default = d.policy(path, v)
+		},
+		"types": func(path string, v interface{}) {
+			cfg.Types = make(map[string]*TypeConfig)
+			d.entries(path, v, func(path string, typeName string, v interface{}) {
+				cfg.Types[typeName] = d.typeConfig(path, v)
+			})
+		},
+	})
+	return cfg
+}
+
+func (d *decoder) typeConfig(path string, v interface{}) *TypeConfig {
+	t := &TypeConfig{}
+	d.fields(path, v, map[string]func(string, interface{}){
+		"default": func(path string, v interface{}) {
+			t.Default = d.policy(path, v)
+		},
+		"methods": func(path string, v interface{}) {
+			t.Methods = make(map[string]*Policy)
+			d.entries(path, v, func(path string, methodName string, v interface{}) {
+				t.Methods[methodName] = d.policy(path, v)
+			})
+		},
+	})
+	return t
+}
+
+func (d *decoder) policy(path string, v interface{}) *Policy {
+	p := &Policy{}
+	d.fields(path, v, map[string]func(string, interface{}){
+		"timeout": func(path string, v interface{}) {
+			p.Timeout = d.positiveDuration(path, v)
+		},
+		"retry": func(path string, v interface{}) {
+			p.Retry = &RetryPolicy{}
+			d.fields(path, v, map[string]func(string, interface{}){
+				"times":          func(path string, v interface{}) { p.Retry.Times = d.positiveInt(path, v) },
+				"waitBase":       func(path string, v interface{}) { p.Retry.WaitBase = d.positiveDuration(path, v) },
+				"disableBackoff": func(path string, v interface{}) { p.Retry.DisableBackoff = d.bool(path, v) },
+			})
+		},
+		"circuitBreaker": func(path string, v interface{}) {
+			cb := &CircuitBreakerPolicy{}
+			p.CircuitBreaker = cb
+			d.fields(path, v, map[string]func(string, interface{}){
+				"errorPercentThresholdToOpen": func(path string, v interface{}) {
+					if cb.ErrorPercentThresholdToOpen = d.positiveInt(path, v); cb.ErrorPercentThresholdToOpen > 100 {
+						d.errorf(path, "must be a percentage between 1 and 100, got %d", cb.ErrorPercentThresholdToOpen)
+					}
+				},
+				"minimumRequestToOpen":               func(path string, v interface{}) { cb.MinimumRequestToOpen = d.positiveInt(path, v) },
+				"successfulRequiredOnHalfOpen":       func(path string, v interface{}) { cb.SuccessfulRequiredOnHalfOpen = d.positiveInt(path, v) },
+				"waitDurationInOpenState":            func(path string, v interface{}) { cb.WaitDurationInOpenState = d.positiveDuration(path, v) },
+				"metricsSlidingWindowBucketQuantity": func(path string, v interface{}) { cb.MetricsSlidingWindowBucketQuantity = d.positiveInt(path, v) },
+				"metricsBucketDuration":              func(path string, v interface{}) { cb.MetricsBucketDuration = d.positiveDuration(path, v) },
+			})
+		},
+		"bulkhead": func(path string, v interface{}) {
+			p.Bulkhead = &BulkheadPolicy{}
+			d.fields(path, v, map[string]func(string, interface{}){
+				"workers":     func(path string, v interface{}) { p.Bulkhead.Workers = d.positiveInt(path, v) },
+				"maxWaitTime": func(path string, v interface{}) { p.Bulkhead.MaxWaitTime = d.positiveDuration(path, v) },
+			})
+		},
+		"concurrencyLimit": func(path string, v interface{}) {
+			p.ConcurrencyLimit = &ConcurrencyLimitPolicy{Limiter: AIMDLimiter}
+			d.fields(path, v, map[string]func(string, interface{}){
+				"limiter": func(path string, v interface{}) {
+					p.ConcurrencyLimit.Limiter = d.string(path, v)
+					if l := p.ConcurrencyLimit.Limiter; l != StaticLimiter && l != AIMDLimiter {
+						d.errorf(path, "unknown limiter %q, must be one of %q or %q", l, StaticLimiter, AIMDLimiter)
+					}
+				},
+				"limit":       func(path string, v interface{}) { p.ConcurrencyLimit.Limit = d.positiveInt(path, v) },
+				"maxWaitTime": func(path string, v interface{}) { p.ConcurrencyLimit.MaxWaitTime = d.positiveDuration(path, v) },
+			})
+			if p.ConcurrencyLimit.Limiter == StaticLimiter && p.ConcurrencyLimit.Limit == 0 {
+				d.errorf(join(path, "limit"), "is required by the %q limiter", StaticLimiter)
+			}
+		},
+	})
+	return p
+}
+
+// fields decodes the fields of the mapping at the given path with the given decoders, unknown fields are reported
+func (d *decoder) fields(path string, v interface{}, decoders map[string]func(path string, v interface{})) {
+	d.entries(path, v, func(fieldPath string, key string, v interface{}) {
+		decode, ok := decoders[key]
+		if !ok {
+			known := make([]string, 0, len(decoders))
+			for k := range decoders {
+				known = append(known, k)
+			}
+			sort.Strings(known)
+			d.errorf(fieldPath, "unknown field, must be one of: %s", strings.Join(known, ", "))
+			return
+		}
+		decode(fieldPath, v)
+	})
+}
+
+// entries iterates over the entries of the mapping at the given path in the order of their keys
+func (d *decoder) entries(path string, v interface{}, fn func(path string, key string, v interface{})) {
+	if v == nil {
+		return
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		d.errorf(path, "must be a mapping, got %s", describe(v))
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		key, ok := k.(string)
+		if !ok {
+			d.errorf(path, "keys must be strings, got %s", describe(k))
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fn(join(path, key), key, m[key])
+	}
+}
+
+func (d *decoder) positiveInt(path string, v interface{}) int {
+	i, ok := v.(int)
+	if !ok {
+		d.errorf(path, "must be an integer, got %s", describe(v))
+		return 0
+	}
+	if i <= 0 {
+		d.errorf(path, "must be greater than 0, got %d", i)
+	}
+	return i
+}
+
+func (d *decoder) positiveDuration(path string, v interface{}) time.Duration {
+	s, ok := v.(string)
+	if !ok {
+		d.errorf(path, "must be a duration such as \"500ms\" or \"1s\", got %s", describe(v))
+		return 0
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		d.errorf(path, "must be a duration such as \"500ms\" or \"1s\", got %q", s)
+		return 0
+	}
+	if duration <= 0 {
+		d.errorf(path, "must be greater than 0, got %s", duration)
+	}
+	return duration
+}
+
+func (d *decoder) bool(path string, v interface{}) bool {
+	b, ok := v.(bool)
+	if !ok {
+		d.errorf(path, "must be a boolean, got %s", describe(v))
+	}
+	return b
+}
+
+func (d *decoder) string(path string, v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		d.errorf(path, "must be a string, got %s", describe(v))
+	}
+	return s
+}
+
+// join appends the key to the given path, keys with dots are quoted to keep the path unambiguous
+func join(path, key string) string {
+	if strings.Contains(key, ".") {
+		key = fmt.Sprintf("%q", key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// describe describes the kind of the given value for error messages
+func describe(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case map[interface{}]interface{}:
+		return "a mapping"
+	case []interface{}:
+		return "a list"
+	case string:
+		return fmt.Sprintf("the string %q", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,189 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+default:
+  retry:
+    times: 1
+    waitBase: 1ms
+types:
+  Client:
+    default:
+      retry:
+        times: 2
+        waitBase: 1ms
+        disableBackoff: true
+    methods:
+      SaveUser:
+        timeout: 10ms
+      GetUser:
+        circuitBreaker:
+          errorPercentThresholdToOpen: 50
+          minimumRequestToOpen: 1
+          waitDurationInOpenState: 1m
+        bulkhead:
+          workers: 2
+          maxWaitTime: 1s
+        concurrencyLimit:
+          limiter: static
+          limit: 5
+`
+
+func TestParseConfig(t *testing.T) {
+	want := &runner.Config{
+		Default: &runner.Policy{
+			Retry: &runner.RetryPolicy{Times: 1, WaitBase: time.Millisecond},
+		},
+		Types: map[string]*runner.TypeConfig{
+			"Client": {
+				Default: &runner.Policy{
+					Retry: &runner.RetryPolicy{Times: 2, WaitBase: time.Millisecond, DisableBackoff: true},
+				},
+				Methods: map[string]*runner.Policy{
+					"SaveUser": {Timeout: 10 * time.Millisecond},
+					"GetUser": {
+						CircuitBreaker: &runner.CircuitBreakerPolicy{
+							ErrorPercentThresholdToOpen: 50,
+							MinimumRequestToOpen:        1,
+							WaitDurationInOpenState:     time.Minute,
+						},
+						Bulkhead:         &runner.BulkheadPolicy{Workers: 2, MaxWaitTime: time.Second},
+						ConcurrencyLimit: &runner.ConcurrencyLimitPolicy{Limiter: runner.StaticLimiter, Limit: 5},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("YAML", func(t *testing.T) {
+		got, err := runner.ParseConfig(strings.NewReader(testConfig))
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		got, err := runner.ParseConfig(strings.NewReader(`{
+  "default": {"retry": {"times": 1, "waitBase": "1ms"}},
+  "types": {
+    "Client": {
+      "default": {"retry": {"times": 2, "waitBase": "1ms", "disableBackoff": true}},
+      "methods": {
+        "SaveUser": {"timeout": "10ms"},
+        "GetUser": {
+          "circuitBreaker": {"errorPercentThresholdToOpen": 50, "minimumRequestToOpen": 1, "waitDurationInOpenState": "1m"},
+          "bulkhead": {"workers": 2, "maxWaitTime": "1s"},
+          "concurrencyLimit": {"limiter": "static", "limit": 5}
+        }
+      }
+    }
+  }
+}`))
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	})
+}
+
+func TestParseConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		wantErrs []string
+	}{
+		{
+			name:     "Not a mapping",
+			config:   `[]`,
+			wantErrs: []string{"(root): must be a mapping, got a list"},
+		},
+		{
+			name:     "Unknown field",
+			config:   "types:\n  Client:\n    methods:\n      GetUser:\n        retries: 3\n",
+			wantErrs: []string{"types.Client.methods.GetUser.retries: unknown field, must be one of: bulkhead, circuitBreaker, concurrencyLimit, retry, timeout"},
+		},
+		{
+			name:     "Invalid duration",
+			config:   "default:\n  timeout: 10\n",
+			wantErrs: []string{`default.timeout: must be a duration such as "500ms" or "1s", got 10`},
+		},
+		{
+			name:   "Multiple errors",
+			config: "types:\n  Client:\n    default:\n      retry:\n        times: 0\n        disableBackoff: yes please\n      circuitBreaker:\n        errorPercentThresholdToOpen: 150\n",
+			wantErrs: []string{
+				"types.Client.default.circuitBreaker.errorPercentThresholdToOpen: must be a percentage between 1 and 100, got 150",
+				`types.Client.default.retry.disableBackoff: must be a boolean, got the string "yes please"`,
+				"types.Client.default.retry.times: must be greater than 0, got 0",
+			},
+		},
+		{
+			name:   "Invalid concurrency limit",
+			config: "default:\n  concurrencyLimit:\n    limiter: vegas\n",
+			wantErrs: []string{
+				`default.concurrencyLimit.limiter: unknown limiter "vegas", must be one of "static" or "aimd"`,
+			},
+		},
+		{
+			name:     "Static limiter without limit",
+			config:   "default:\n  concurrencyLimit:\n    limiter: static\n",
+			wantErrs: []string{`default.concurrencyLimit.limit: is required by the "static" limiter`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runner.ParseConfig(strings.NewReader(tt.config))
+			require.Error(t, err)
+			require.Equal(t, strings.Join(tt.wantErrs, "\n"), err.Error())
+
+			var cfgErr *runner.ConfigError
+			require.True(t, errors.As(err, &cfgErr))
+		})
+	}
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := runner.FromConfig(strings.NewReader("default: [\n"))
+		require.Error(t, err)
+	})
+}
+
+func TestFromConfig(t *testing.T) {
+	f, err := runner.FromConfig(strings.NewReader(testConfig))
+	require.NoError(t, err)
+
+	t.Run("Type Default", func(t *testing.T) {
+		require.Equal(t, 3, attempts(t, f.GetRunner("Client.DeleteUser")))
+	})
+
+	t.Run("Instance Name", func(t *testing.T) {
+		require.Equal(t, 3, attempts(t, f.GetRunner("payments.Client.DeleteUser")))
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		require.Equal(t, 2, attempts(t, f.GetRunner("OtherClient.DeleteUser")))
+	})
+
+	t.Run("Method", func(t *testing.T) {
+		err := f.GetRunner("Client.SaveUser").Run(context.Background(), func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Circuit Breaker State Is Not Shared", func(t *testing.T) {
+		require.Equal(t, 1, attempts(t, f.GetRunner("Client.GetUser")))
+		require.Error(t, f.GetRunner("Client.GetUser").Run(context.Background(), func(ctx context.Context) error {
+			t.Fatal("circuit should be open")
+			return nil
+		}))
+		require.NoError(t, f.GetRunner("payments.Client.GetUser").Run(context.Background(), func(ctx context.Context) error {
+			return nil
+		}))
+	})
+}
@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"context"
+	"github.com/slok/goresilience"
+	"time"
+)
+
+// Fault describes the faults injected into the attempts of a runner, the rates are probabilities between 0 and 1
+type Fault struct {
+	// ErrorRate is the probability of an attempt failing with Err instead of calling the delegate
+	ErrorRate float64
+	// Err is the error injected, ErrChaos when nil
+	Err error
+	// Latency is the delay added before the attempts
+	Latency time.Duration
+	// LatencyRate is the probability of an attempt being delayed by Latency, every attempt is delayed when zero
+	LatencyRate float64
+	// PanicRate is the probability of an attempt panicking with ErrChaosPanic instead of calling the delegate
+	PanicRate float64
+}
+
+// FaultSource provides the faults injected by the middlewares created with FaultMiddleware
+type FaultSource interface {
+	// Fault returns the fault injected into the next attempt of the runner with the given name, false if no fault is
+	// injected
+	Fault(name string) (Fault, bool)
+	// Float64 returns the next random number, in [0.0,1.0), drawn for the runner with the given name
+	Float64(name string) float64
+}
+
+// FaultMiddleware creates the middleware that injects the faults given by the source into the attempts of the runner
+// with the given name. The faults are injected right before calling the runner's function, so these go through all the
+// middlewares of the runner wherever the middleware is placed in its chain (e.g. the injected errors are retried).
+//
+// The latency, panic and error of an attempt are drawn in this order regardless of the fault's rates, so changing a
+// rate doesn't change the outcome of the others.
+func FaultMiddleware(name string, source FaultSource) goresilience.Middleware {
+	return func(next goresilience.Runner) goresilience.Runner {
+		next = goresilience.SanitizeRunner(next)
+		return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+			return next.Run(ctx, func(ctx context.Context) error {
+				fault, ok := source.Fault(name)
+				if !ok {
+					return f(ctx)
+				}
+
+				delayed := source.Float64(name)
+				delay := fault.Latency > 0 && (fault.LatencyRate == 0 || delayed < fault.LatencyRate)
+				panics := source.Float64(name) < fault.PanicRate
+				fails := source.Float64(name) < fault.ErrorRate
+				if delay {
+					timer := time.NewTimer(fault.Latency)
+					select {
+					case <-timer.C:
+					case <-ctx.Done():
+						timer.Stop()
+						return ctx.Err()
+					}
+				}
+				if panics {
+					panic(ErrChaosPanic)
+				}
+				if fails {
+					if fault.Err != nil {
+						return fault.Err
+					}
+					return ErrChaos
+				}
+				return f(ctx)
+			})
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package runner_test
+
+import (
+	"errors"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// fixedFaults is a FaultSource injecting the same fault into every runner, drawing the given numbers in order
+type fixedFaults struct {
+	fault runner.Fault
+	draws []float64
+}
+
+func (s *fixedFaults) Fault(_ string) (runner.Fault, bool) {
+	return s.fault, true
+}
+
+func (s *fixedFaults) Float64(_ string) float64 {
+	draw := s.draws[0]
+	s.draws = s.draws[1:]
+	return draw
+}
+
+func TestFaultMiddleware(t *testing.T) {
+	errCustom := errors.New("custom")
+
+	tests := []struct {
+		name      string
+		fault     runner.Fault
+		draws     []float64
+		wantErr   error
+		wantPanic bool
+		wantDelay bool
+	}{
+		{
+			name:  "No Fault Drawn",
+			fault: runner.Fault{ErrorRate: 0.5, PanicRate: 0.5, Latency: time.Hour, LatencyRate: 0.5},
+			draws: []float64{0.5, 0.5, 0.5},
+		},
+		{
+			name:    "Error",
+			fault:   runner.Fault{ErrorRate: 0.5},
+			draws:   []float64{0, 0, 0.4},
+			wantErr: runner.ErrChaos,
+		},
+		{
+			name:    "Custom Error",
+			fault:   runner.Fault{ErrorRate: 0.5, Err: errCustom},
+			draws:   []float64{0, 0, 0.4},
+			wantErr: errCustom,
+		},
+		{
+			name:      "Panic",
+			fault:     runner.Fault{ErrorRate: 1, PanicRate: 0.5},
+			draws:     []float64{0, 0.4, 0},
+			wantPanic: true,
+		},
+		{
+			name:      "Latency",
+			fault:     runner.Fault{Latency: 10 * time.Millisecond, LatencyRate: 0.5},
+			draws:     []float64{0.4, 0, 0},
+			wantDelay: true,
+		},
+		{
+			name:      "Latency Without Rate",
+			fault:     runner.Fault{Latency: 10 * time.Millisecond},
+			draws:     []float64{0.9, 0, 0},
+			wantDelay: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := runner.Wrap(runner.NewFactory(), func(name string) goresilience.Middleware {
+				return runner.FaultMiddleware(name, &fixedFaults{fault: tt.fault, draws: tt.draws})
+			})
+			if tt.wantPanic {
+				require.PanicsWithValue(t, runner.ErrChaosPanic, func() {
+					_ = run(f, "Client.GetUser")
+				})
+				return
+			}
+
+			start := time.Now()
+			require.ErrorIs(t, run(f, "Client.GetUser"), tt.wantErr)
+			require.Equal(t, tt.wantDelay, time.Since(start) >= 10*time.Millisecond)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"github.com/slok/goresilience"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// chain is the chain of middlewares that a runner executes with, the chain is retired when it's replaced by an update of
+// the factory's policies and its resources are released once the executions in-flight are done.
+type chain struct {
+	runner goresilience.Runner
+	// policy is the policy the chain was created from, nil if it wasn't created from a Config
+	policy *Policy
+	// release releases the resources held by the middlewares, may be nil
+	release func()
+
+	inflight    atomic.Int64
+	retired     atomic.Bool
+	releaseOnce sync.Once
+}
+
+// newChain creates a chain of the given middlewares
+func newChain(middlewares []goresilience.Middleware) *chain {
+	return &chain{runner: goresilience.RunnerChain(middlewares...)}
+}
+
+// done marks the end of an execution, releasing the chain if it was the last execution of a retired chain
+func (c *chain) done() {
+	if c.inflight.Add(-1) == 0 && c.retired.Load() {
+		c.releaseResources()
+	}
+}
+
+// retire marks the chain as replaced, its resources are released right away if there are no executions in-flight
+func (c *chain) retire() {
+	c.retired.Store(true)
+	if c.inflight.Load() == 0 {
+		c.releaseResources()
+	}
+}
+
+func (c *chain) releaseResources() {
+	c.releaseOnce.Do(func() {
+		if c.release != nil {
+			c.release()
+		}
+	})
+}
+
+// reloadableRunner is the Runner handed out by the Factory, it executes with the current chain of its name which can be
+// swapped atomically by the updates of the factory's policies
+type reloadableRunner struct {
+	current atomic.Pointer[chain]
+}
+
+// Run satisfies the goresilience.Runner interface, the execution finishes on the chain it started with even if the chain
+// is swapped in the meantime
+func (r *reloadableRunner) Run(ctx context.Context, f goresilience.Func) error {
+	c := r.acquire()
+	defer c.done()
+	return c.runner.Run(ctx, f)
+}
+
+// acquire registers an execution in the current chain
+func (r *reloadableRunner) acquire() *chain {
+	for {
+		c := r.current.Load()
+		c.inflight.Add(1)
+		if !c.retired.Load() {
+			return c
+		}
+		// The chain was swapped after it was loaded, try again with the new chain
+		c.done()
+	}
+}
+
+// swap replaces the current chain with the given chain
+func (r *reloadableRunner) swap(next *chain) {
+	r.current.Swap(next).retire()
+}
+
+// Update replaces the policies of the factory with the ones from the given configuration, this also applies to the
+// runners that were already created. The executions in-flight finish with their previous policy while new executions
+// use the new policy. The runners whose policy didn't change keep their middlewares, and thus their state such as
+// the circuit breaker's. This is thread-safe.
+func (f *Factory) Update(cfg *Config) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.newChain = cfg.newChain
+	for name, r := range f.runners {
+		current := r.current.Load()
+		if current.policy != nil && reflect.DeepEqual(current.policy, cfg.policy(name)) {
+			continue
+		}
+		r.swap(f.newChain(name))
+	}
+}
+
+// UpdateFromConfig replaces the policies of the factory with the ones read from the given YAML or JSON configuration,
+// the policies are left untouched if the configuration is invalid. See Update.
+func (f *Factory) UpdateFromConfig(r io.Reader) error {
+	cfg, err := ParseConfig(r)
+	if err != nil {
+		return fmt.Errorf("failed to update factory; error=%w", err)
+	}
+	f.Update(cfg)
+	return nil
+}
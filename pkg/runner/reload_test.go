@@ -0,0 +1,142 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errFailed = errors.New("failed")
+
+func mustParseConfig(t *testing.T, config string) *runner.Config {
+	cfg, err := runner.ParseConfig(strings.NewReader(config))
+	require.NoError(t, err)
+	return cfg
+}
+
+// attempts counts the times the runner executes a function that always fails
+func attempts(t *testing.T, r goresilience.Runner) int {
+	calls := 0
+	err := r.Run(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errFailed
+	})
+	require.Error(t, err)
+	return calls
+}
+
+func TestFactory_Update(t *testing.T) {
+	retryOnce := "default:\n  retry:\n    times: 1\n    waitBase: 1ms\n"
+	noRetries := "default:\n  timeout: 1s\n"
+
+	t.Run("Existing Runners", func(t *testing.T) {
+		f := runner.NewConfigFactory(mustParseConfig(t, retryOnce))
+		r := f.GetRunner("Client.GetUser")
+		require.Equal(t, 2, attempts(t, r))
+
+		f.Update(mustParseConfig(t, noRetries))
+		require.Same(t, r, f.GetRunner("Client.GetUser"))
+		require.Equal(t, 1, attempts(t, r))
+		require.Equal(t, 1, attempts(t, f.GetRunner("Client.SaveUser")))
+	})
+
+	t.Run("Factory Without Config", func(t *testing.T) {
+		f := runner.NewFactory()
+		r := f.GetRunner("Client.GetUser")
+		require.Equal(t, 1, attempts(t, r))
+
+		f.Update(mustParseConfig(t, retryOnce))
+		require.Equal(t, 2, attempts(t, r))
+	})
+
+	t.Run("In-flight Executions Finish On Previous Policy", func(t *testing.T) {
+		f := runner.NewConfigFactory(mustParseConfig(t, retryOnce))
+		r := f.GetRunner("Client.GetUser")
+
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		calls := 0
+		done := make(chan error)
+		go func() {
+			done <- r.Run(context.Background(), func(ctx context.Context) error {
+				calls++
+				if calls == 1 {
+					close(started)
+					<-unblock
+				}
+				return errFailed
+			})
+		}()
+
+		<-started
+		f.Update(mustParseConfig(t, noRetries))
+		require.Equal(t, 1, attempts(t, r))
+
+		close(unblock)
+		require.ErrorIs(t, <-done, errFailed)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Circuit Breaker State", func(t *testing.T) {
+		breaker := `
+types:
+  Client:
+    methods:
+      GetUser:
+        circuitBreaker:
+          minimumRequestToOpen: 1
+          waitDurationInOpenState: 1m
+`
+		f := runner.NewConfigFactory(mustParseConfig(t, breaker))
+		r := f.GetRunner("Client.GetUser")
+		require.Equal(t, 1, attempts(t, r))
+		require.Equal(t, 0, attempts(t, r), "circuit should be open")
+
+		// The policy of the runner didn't change, its circuit remains open
+		f.Update(mustParseConfig(t, breaker+noRetries))
+		require.Equal(t, 0, attempts(t, r), "circuit should remain open")
+
+		// The policy of the runner changed, a new circuit is used
+		f.Update(mustParseConfig(t, strings.Replace(breaker, "1m", "2m", 1)))
+		require.Equal(t, 1, attempts(t, r), "circuit should be closed")
+	})
+
+	t.Run("Concurrent Executions", func(t *testing.T) {
+		f := runner.NewConfigFactory(mustParseConfig(t, "default:\n  bulkhead:\n    workers: 2\n"))
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					require.NoError(t, f.GetRunner("Client.GetUser").Run(context.Background(), func(ctx context.Context) error {
+						return nil
+					}))
+				}
+			}()
+		}
+		for i := 0; i < 20; i++ {
+			f.Update(mustParseConfig(t, fmt.Sprintf("default:\n  bulkhead:\n    workers: %d\n", 1+i%2)))
+			time.Sleep(time.Millisecond)
+		}
+		wg.Wait()
+	})
+}
+
+func TestFactory_UpdateFromConfig(t *testing.T) {
+	f := runner.NewConfigFactory(mustParseConfig(t, "default:\n  retry:\n    times: 1\n    waitBase: 1ms\n"))
+	r := f.GetRunner("Client.GetUser")
+
+	require.Error(t, f.UpdateFromConfig(strings.NewReader("default:\n  retry:\n    times: -1\n")))
+	require.Equal(t, 2, attempts(t, r))
+
+	require.NoError(t, f.UpdateFromConfig(strings.NewReader("default:\n  timeout: 1s\n")))
+	require.Equal(t, 1, attempts(t, r))
+}
@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"fmt"
+	"github.com/slok/goresilience"
+	"path"
+	"regexp"
+)
+
+// Rule routes the runners whose name it matches to a chain of middlewares
+type Rule struct {
+	exact       string
+	match       func(name string) bool
+	isDefault   bool
+	middlewares []goresilience.Middleware
+}
+
+// Route creates a Rule for the runners whose name matches the given pattern, the pattern is either an exact runner name
+// (e.g. "Client.GetUser") or a glob as supported by path.Match (e.g. "*.Get*"). Route panics if the pattern is malformed.
+func Route(pattern string, middlewares ...goresilience.Middleware) Rule {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(fmt.Sprintf("invalid route pattern %q: %v", pattern, err))
+	}
+	if !isGlob(pattern) {
+		return Rule{exact: pattern, middlewares: middlewares}
+	}
+	return Rule{
+		match: func(name string) bool {
+			// The pattern was validated so no error can be returned
+			matched, _ := path.Match(pattern, name)
+			return matched
+		},
+		middlewares: middlewares,
+	}
+}
+
+// RouteRegexp creates a Rule for the runners whose name matches the given regular expression
+func RouteRegexp(expr *regexp.Regexp, middlewares ...goresilience.Middleware) Rule {
+	return Rule{match: expr.MatchString, middlewares: middlewares}
+}
+
+// Default creates a Rule for the runners whose name isn't matched by any other Rule
+func Default(middlewares ...goresilience.Middleware) Rule {
+	return Rule{isDefault: true, middlewares: middlewares}
+}
+
+// NewRoutingFactory creates an instance of a Runner factory that resolves the middlewares of each runner from the given
+// rules. Rules for exact names take precedence over the rest of the rules, which are then tried in the order given. The
+// runners that aren't matched by any rule use the middlewares of the Default rule, or no middlewares at all if there is
+// none. Like the factory created with NewFactory, this returns a singleton instance of a runner for each unique runner
+// identifier.
+func NewRoutingFactory(rules ...Rule) *Factory {
+	exact := make(map[string][]goresilience.Middleware)
+	var patterns []Rule
+	var defaultMiddlewares []goresilience.Middleware
+	for _, rule := range rules {
+		switch {
+		case rule.isDefault:
+			defaultMiddlewares = rule.middlewares
+		case rule.match != nil:
+			patterns = append(patterns, rule)
+		default:
+			if _, ok := exact[rule.exact]; !ok {
+				exact[rule.exact] = rule.middlewares
+			}
+		}
+	}
+
+	return newFactory(func(name string) *chain {
+		if middlewares, ok := exact[name]; ok {
+			return newChain(middlewares)
+		}
+		for _, rule := range patterns {
+			if rule.match(name) {
+				return newChain(rule.middlewares)
+			}
+		}
+		return newChain(defaultMiddlewares)
+	})
+}
+
+// isGlob determines whether the given pattern contains any of the special characters supported by path.Match
+func isGlob(pattern string) bool {
+	for _, c := range pattern {
+		switch c {
+		case '*', '?', '[', '\\':
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package runner_test
+
+import (
+	"context"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/slok/goresilience"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestNewRoutingFactory(t *testing.T) {
+	var mu sync.Mutex
+	var called []string
+	// tag is a middleware that records its tag when the runner is executed
+	tag := func(name string) goresilience.Middleware {
+		return func(r goresilience.Runner) goresilience.Runner {
+			return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+				mu.Lock()
+				called = append(called, name)
+				mu.Unlock()
+				return r.Run(ctx, f)
+			})
+		}
+	}
+
+	tests := []struct {
+		name       string
+		rules      []runner.Rule
+		runnerName string
+		want       []string
+	}{
+		{
+			name:       "Exact",
+			rules:      []runner.Rule{runner.Route("Client.GetUser", tag("exact")), runner.Default(tag("default"))},
+			runnerName: "Client.GetUser",
+			want:       []string{"exact"},
+		},
+		{
+			name:       "Exact takes precedence",
+			rules:      []runner.Rule{runner.Route("*.Get*", tag("glob")), runner.Route("Client.GetUser", tag("exact"))},
+			runnerName: "Client.GetUser",
+			want:       []string{"exact"},
+		},
+		{
+			name:       "Glob",
+			rules:      []runner.Rule{runner.Route("*.Get*", tag("retry"), tag("timeout")), runner.Default(tag("timeout"))},
+			runnerName: "Client.GetUser",
+			want:       []string{"retry", "timeout"},
+		},
+		{
+			name:       "Glob matches instance names",
+			rules:      []runner.Rule{runner.Route("*.Get*", tag("glob"))},
+			runnerName: "payments.Client.GetUser",
+			want:       []string{"glob"},
+		},
+		{
+			name:       "Regexp",
+			rules:      []runner.Rule{runner.RouteRegexp(regexp.MustCompile(`\.(Save|Delete)\w*$`), tag("regexp")), runner.Default(tag("default"))},
+			runnerName: "Client.SaveUser",
+			want:       []string{"regexp"},
+		},
+		{
+			name:       "First pattern wins",
+			rules:      []runner.Rule{runner.Route("Client.*", tag("first")), runner.RouteRegexp(regexp.MustCompile(`^Client\.`), tag("second"))},
+			runnerName: "Client.SaveUser",
+			want:       []string{"first"},
+		},
+		{
+			name:       "Default",
+			rules:      []runner.Rule{runner.Route("*.Get*", tag("glob")), runner.Default(tag("default"))},
+			runnerName: "Client.SaveUser",
+			want:       []string{"default"},
+		},
+		{
+			name:       "No Default",
+			rules:      []runner.Rule{runner.Route("*.Get*", tag("glob"))},
+			runnerName: "Client.SaveUser",
+			want:       nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = nil
+			f := runner.NewRoutingFactory(tt.rules...)
+			require.NoError(t, f.GetRunner(tt.runnerName).Run(context.Background(), func(ctx context.Context) error {
+				return nil
+			}))
+			require.Equal(t, tt.want, called)
+		})
+	}
+
+	t.Run("Singleton Runners", func(t *testing.T) {
+		created := 0
+		f := runner.NewRoutingFactory(runner.Default(func(r goresilience.Runner) goresilience.Runner {
+			created++
+			return r
+		}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				f.GetRunner("Client.GetUser")
+			}()
+		}
+		wg.Wait()
+		require.Same(t, f.GetRunner("Client.GetUser"), f.GetRunner("Client.GetUser"))
+		require.Equal(t, 1, created)
+	})
+
+	t.Run("Invalid Pattern", func(t *testing.T) {
+		require.Panics(t, func() {
+			runner.Route("Client.[", tag("invalid"))
+		})
+	})
+}
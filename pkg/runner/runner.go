@@ -2,26 +2,88 @@ package runner
 
 import (
 	"github.com/slok/goresilience"
+	"sort"
 	"sync"
 )
 
 // Factory of runners
 type Factory struct {
-	mu          sync.RWMutex
-	runners     map[string]goresilience.Runner
-	middlewares []goresilience.Middleware
+	mu       sync.RWMutex
+	runners  map[string]*reloadableRunner
+	newChain func(name string) *chain
 }
 
 // NewFactory creates an instance of a Runner factory that will create runners on demand if they don't exist otherwise
 // return a singleton instance of a runner for each unique runner identifier.
 func NewFactory(middlewares ...goresilience.Middleware) *Factory {
+	return newFactory(func(_ string) *chain {
+		return newChain(middlewares)
+	})
+}
+
+// NamedMiddleware creates the middleware of the runner with the given name (e.g. "Client.GetUser"), allowing the
+// middleware to behave differently for each runner. It's called once when the runner's chain is created.
+type NamedMiddleware func(name string) goresilience.Middleware
+
+// NewNamedFactory creates an instance of a Runner factory like NewFactory, the middlewares of each runner are created
+// from the runner's name
+func NewNamedFactory(middlewares ...NamedMiddleware) *Factory {
+	return newFactory(func(name string) *chain {
+		chainMiddlewares := make([]goresilience.Middleware, 0, len(middlewares))
+		for _, m := range middlewares {
+			chainMiddlewares = append(chainMiddlewares, m(name))
+		}
+		return newChain(chainMiddlewares)
+	})
+}
+
+// Unnamed adapts a middleware shared by all the runners to a NamedMiddleware
+func Unnamed(middleware goresilience.Middleware) NamedMiddleware {
+	return func(_ string) goresilience.Middleware {
+		return middleware
+	}
+}
+
+// RunnerFactory creates runners by name, it is satisfied by Factory and by the factories returned by Wrap
+type RunnerFactory interface {
+	GetRunner(name string) goresilience.Runner
+}
+
+// Wrap creates a runner factory whose runners are the runners of the given factory wrapped by the middlewares created
+// from the runner's name, the first middleware being the outermost. The middlewares are kept in place when the
+// policies of the given factory are updated as these wrap its runners rather than being part of their chains.
+func Wrap(runnerFactory RunnerFactory, middlewares ...NamedMiddleware) RunnerFactory {
+	if runnerFactory == nil {
+		panic("provided nil runner factory")
+	}
+	return &wrappedFactory{runnerFactory: runnerFactory, middlewares: middlewares}
+}
+
+// wrappedFactory is the runner factory created by Wrap
+type wrappedFactory struct {
+	runnerFactory RunnerFactory
+	middlewares   []NamedMiddleware
+}
+
+// GetRunner returns the runner with the given name from the underlying factory wrapped by the middlewares
+func (f *wrappedFactory) GetRunner(name string) goresilience.Runner {
+	runner := f.runnerFactory.GetRunner(name)
+	for i := len(f.middlewares) - 1; i >= 0; i-- {
+		runner = f.middlewares[i](name)(runner)
+	}
+	return runner
+}
+
+// newFactory creates an instance of a Runner factory that creates the chain of each runner by its name
+func newFactory(newChain func(name string) *chain) *Factory {
 	return &Factory{
-		runners:     make(map[string]goresilience.Runner),
-		middlewares: middlewares,
+		runners:  make(map[string]*reloadableRunner),
+		newChain: newChain,
 	}
 }
 
 // GetRunner retrieves a runner with the given name, this is guaranteed to always return a Runner. This is thread-safe.
+// The runner remains valid across updates of the factory's policies, see Update.
 func (f *Factory) GetRunner(name string) goresilience.Runner {
 	f.mu.RLock()
 	if r, ok := f.runners[name]; ok {
@@ -38,7 +100,40 @@ func (f *Factory) GetRunner(name string) goresilience.Runner {
 	if r, ok := f.runners[name]; ok {
 		return r
 	}
-	runner := goresilience.RunnerChain(f.middlewares...)
+	runner := &reloadableRunner{}
+	runner.current.Store(f.newChain(name))
 	f.runners[name] = runner
 	return runner
 }
+
+// Names returns the sorted names of the runners created by the factory. This is thread-safe.
+func (f *Factory) Names() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	names := make([]string, 0, len(f.runners))
+	for name := range f.runners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Reset replaces the middlewares of the runner with the given name with new instances, discarding their state (e.g. an
+// open circuit breaker). The executions in-flight finish with the previous middlewares. Nothing is done if the runner
+// doesn't exist. This is thread-safe.
+func (f *Factory) Reset(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r, ok := f.runners[name]; ok {
+		r.swap(f.newChain(name))
+	}
+}
+
+// Warmup creates the runners with the given names ahead of their first use. This is thread-safe.
+func (f *Factory) Warmup(names ...string) {
+	for _, name := range names {
+		f.GetRunner(name)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"github.com/csueiras/reinforcer/pkg/runner"
 	"github.com/slok/goresilience"
+	"github.com/slok/goresilience/retry"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestFactory_GetRunner(t *testing.T) {
@@ -36,3 +38,127 @@ func TestFactory_GetRunner(t *testing.T) {
 	require.Equal(t, 4, mwCalled)
 	require.Equal(t, 2, mwCreated)
 }
+
+func TestFactory_Names(t *testing.T) {
+	f := runner.NewFactory()
+	require.Empty(t, f.Names())
+
+	f.GetRunner("Client.SaveUser")
+	f.GetRunner("Client.GetUser")
+	f.GetRunner("Client.SaveUser")
+	require.Equal(t, []string{"Client.GetUser", "Client.SaveUser"}, f.Names())
+}
+
+func TestFactory_Reset(t *testing.T) {
+	f := runner.NewConfigFactory(mustParseConfig(t, `
+default:
+  circuitBreaker:
+    minimumRequestToOpen: 1
+    waitDurationInOpenState: 1m
+`))
+	r := f.GetRunner("Client.GetUser")
+	require.Equal(t, 1, attempts(t, r))
+	require.Equal(t, 0, attempts(t, r), "circuit should be open")
+
+	f.Reset("Client.GetUser")
+	require.Same(t, r, f.GetRunner("Client.GetUser"))
+	require.Equal(t, 1, attempts(t, r), "circuit should be closed")
+
+	f.Reset("Client.SaveUser")
+	require.Equal(t, []string{"Client.GetUser"}, f.Names())
+}
+
+func TestFactory_Warmup(t *testing.T) {
+	mwCreated := 0
+	f := runner.NewFactory(func(r goresilience.Runner) goresilience.Runner {
+		mwCreated++
+		return r
+	})
+
+	f.Warmup("Client.GetUser", "Client.SaveUser")
+	require.Equal(t, []string{"Client.GetUser", "Client.SaveUser"}, f.Names())
+	require.Equal(t, 2, mwCreated)
+
+	f.GetRunner("Client.GetUser")
+	require.Equal(t, 2, mwCreated)
+}
+
+func TestNewNamedFactory(t *testing.T) {
+	var built []string
+	named := func(name string) goresilience.Middleware {
+		built = append(built, name)
+		return func(next goresilience.Runner) goresilience.Runner {
+			next = goresilience.SanitizeRunner(next)
+			return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+				if name == "Client.SaveUser" {
+					return errFailed
+				}
+				return next.Run(ctx, f)
+			})
+		}
+	}
+	f := runner.NewNamedFactory(runner.Unnamed(retry.NewMiddleware(retry.Config{Times: 1, WaitBase: time.Millisecond})), named)
+
+	require.Equal(t, 2, attempts(t, f.GetRunner("Client.GetUser")))
+	require.Equal(t, 0, attempts(t, f.GetRunner("Client.SaveUser")))
+	require.Equal(t, []string{"Client.GetUser", "Client.SaveUser"}, built)
+}
+
+func TestWrap(t *testing.T) {
+	var order []string
+	named := func(tag string) runner.NamedMiddleware {
+		return func(name string) goresilience.Middleware {
+			return func(next goresilience.Runner) goresilience.Runner {
+				next = goresilience.SanitizeRunner(next)
+				return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+					order = append(order, tag+":"+name)
+					return next.Run(ctx, f)
+				})
+			}
+		}
+	}
+	f := runner.NewConfigFactory(mustParseConfig(t, "default:\n  timeout: 1s\n"))
+	r := runner.Wrap(f, named("outer"), named("inner")).GetRunner("Client.GetUser")
+
+	require.Equal(t, 1, attempts(t, r))
+	require.Equal(t, []string{"outer:Client.GetUser", "inner:Client.GetUser"}, order)
+
+	// The wrapped runner follows the updates of the underlying factory
+	f.Update(mustParseConfig(t, "default:\n  retry:\n    times: 1\n    waitBase: 1ms\n"))
+	order = nil
+	require.Equal(t, 2, attempts(t, r))
+	require.Equal(t, []string{"outer:Client.GetUser", "inner:Client.GetUser"}, order)
+
+	require.Panics(t, func() {
+		runner.Wrap(nil)
+	})
+}
+
+// BenchmarkFactory_GetRunner compares looking up the runner in the factory on every call with resolving it once, as done
+// by the generated proxies when created
+func BenchmarkFactory_GetRunner(b *testing.B) {
+	ctx := context.Background()
+	fn := func(ctx context.Context) error {
+		return nil
+	}
+
+	b.Run("Runner Per Call", func(b *testing.B) {
+		f := runner.NewFactory()
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = f.GetRunner("Client.GetUser").Run(ctx, fn)
+			}
+		})
+	})
+
+	b.Run("Resolved Runner", func(b *testing.B) {
+		r := runner.NewFactory().GetRunner("Client.GetUser")
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = r.Run(ctx, fn)
+			}
+		})
+	})
+}
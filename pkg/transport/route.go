@@ -0,0 +1,48 @@
+package transport
+
+import (
+	"net/http"
+	"strings"
+)
+
+// unmatchedRoute is the route of the requests that don't match any of the route templates
+const unmatchedRoute = "*"
+
+// route is a path template whose segments are either literals or variables (e.g. "/users/{id}")
+type route struct {
+	template string
+	segments []string
+}
+
+func newRoute(template string) *route {
+	return &route{
+		template: template,
+		segments: strings.Split(strings.Trim(template, "/"), "/"),
+	}
+}
+
+// matches determines whether the given path matches the template, the variables match any single segment
+func (r *route) matches(segments []string) bool {
+	if len(segments) != len(r.segments) {
+		return false
+	}
+	for i, s := range r.segments {
+		isVariable := strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}")
+		if !isVariable && s != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// runnerName is the name of the runner for the given request, the request's method followed by the template of the
+// first route matching its path (e.g. "GET /users/{id}")
+func runnerName(routes []*route, req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for _, r := range routes {
+		if r.matches(segments) {
+			return req.Method + " " + r.template
+		}
+	}
+	return req.Method + " " + unmatchedRoute
+}
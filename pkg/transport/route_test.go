@@ -0,0 +1,48 @@
+package transport_test
+
+import (
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/csueiras/reinforcer/pkg/transport"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransport_RunnerNames(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{method: http.MethodGet, path: "/users/1", want: "GET /users/{id}"},
+		{method: http.MethodDelete, path: "/users/2/", want: "DELETE /users/{id}"},
+		{method: http.MethodPost, path: "/users", want: "POST /users"},
+		{method: http.MethodGet, path: "/users/1/orders/7", want: "GET /users/{id}/orders/{orderID}"},
+		{method: http.MethodGet, path: "/users/1/orders/latest", want: "GET /users/{id}/orders/latest"},
+		{method: http.MethodGet, path: "/users/1/friends", want: "GET *"},
+		{method: http.MethodGet, path: "/", want: "GET *"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	f := &recordingFactory{Factory: runner.NewFactory()}
+	client := &http.Client{Transport: transport.New(ts.Client().Transport, f, transport.WithRoutes(
+		"/users",
+		"/users/{id}",
+		"/users/{id}/orders/latest",
+		"/users/{id}/orders/{orderID}",
+	))}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader(""))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, tt.want, f.names[len(f.names)-1])
+		})
+	}
+}
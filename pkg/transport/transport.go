@@ -0,0 +1,187 @@
+// Package transport provides an http.RoundTripper that executes the requests in the runners of a runner factory, bringing
+// the middlewares of the reinforced proxies to the HTTP clients:
+//
+//	client := &http.Client{
+//	    Transport: transport.New(http.DefaultTransport, runner.NewFactory(...), transport.WithRoutes("/users/{id}")),
+//	}
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/slok/goresilience"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// maxDiscardBytes is the maximum number of bytes read from the bodies of the discarded responses, reading these allows
+// their connections to be reused
+const maxDiscardBytes = 4 << 10
+
+// ErrRetryableResponse is the error returned to the middlewares by the attempts whose response is retryable, the
+// response of the last attempt is returned to the caller
+var ErrRetryableResponse = errors.New("retryable response")
+
+// RunnerFactory creates the runners of the requests, it is satisfied by runner.Factory
+type RunnerFactory interface {
+	GetRunner(name string) goresilience.Runner
+}
+
+// Option configures the Transport
+type Option func(t *Transport)
+
+// WithRoutes names the runners of the requests after the first of the given path templates matching their path, the
+// templates are made of literal segments and variables matching any single segment (e.g. "/users/{id}/orders"). The
+// runners are named by the request's method followed by the template (e.g. "GET /users/{id}/orders"), the requests that
+// don't match any template share the runner of their method (e.g. "GET *").
+func WithRoutes(templates ...string) Option {
+	return func(t *Transport) {
+		for _, template := range templates {
+			t.routes = append(t.routes, newRoute(template))
+		}
+	}
+}
+
+// WithRetryableResponse determines which responses should be retried, by default RetryableResponse is used
+func WithRetryableResponse(fn func(resp *http.Response) bool) Option {
+	return func(t *Transport) {
+		t.retryableResponse = fn
+	}
+}
+
+// RetryableResponse is true for the responses with a 5xx or 429 (Too Many Requests) status
+func RetryableResponse(resp *http.Response) bool {
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// Transport is an http.RoundTripper that executes the requests in the runners of a runner factory, the requests are
+// attempted as many times as the runner's middlewares determine and their bodies are rewound for every attempt
+type Transport struct {
+	base              http.RoundTripper
+	runnerFactory     RunnerFactory
+	routes            []*route
+	retryableResponse func(resp *http.Response) bool
+}
+
+// New creates a Transport that executes the requests with the given base transport, http.DefaultTransport is used when
+// nil
+func New(base http.RoundTripper, runnerFactory RunnerFactory, options ...Option) *Transport {
+	if runnerFactory == nil {
+		panic("provided nil runner factory")
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &Transport{
+		base:              base,
+		runnerFactory:     runnerFactory,
+		retryableResponse: RetryableResponse,
+	}
+	for _, o := range options {
+		o(t)
+	}
+	return t
+}
+
+// RoundTrip executes the request in the runner named after its route
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	getBody, err := rewindableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The attempts are numbered and tracked behind a lock as a middleware (e.g. a timeout) could give up on an attempt
+	// that is still in-flight, only the latest attempt keeps its response and the responses of the others are discarded
+	var mu sync.Mutex
+	var resp *http.Response
+	attempts := 0
+	done := false
+
+	err = t.runnerFactory.GetRunner(runnerName(t.routes, req)).Run(req.Context(), func(ctx context.Context) error {
+		mu.Lock()
+		if resp != nil {
+			discard(resp)
+			resp = nil
+		}
+		attempt := attempts
+		attempts++
+		mu.Unlock()
+
+		attemptReq := req.Clone(ctx)
+		if getBody != nil && (attempt > 0 || req.GetBody == nil) {
+			body, err := getBody()
+			if err != nil {
+				return err
+			}
+			attemptReq.Body = body
+		}
+
+		attemptResp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if done || attempt != attempts-1 {
+			// The attempt was given up on, the response of a newer attempt or the caller's prevails
+			discard(attemptResp)
+			return context.Canceled
+		}
+		if resp != nil {
+			discard(resp)
+		}
+		resp = attemptResp
+		if t.retryableResponse(attemptResp) {
+			return ErrRetryableResponse
+		}
+		return nil
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	done = true
+
+	if attempts == 0 && req.Body != nil && req.GetBody != nil {
+		// The body of the request is closed by the base transport, unless the request was never attempted
+		_ = req.Body.Close()
+	}
+	if resp != nil && (err == nil || errors.Is(err, ErrRetryableResponse)) {
+		return resp, nil
+	}
+	if resp != nil {
+		discard(resp)
+	}
+	if err == nil {
+		err = errors.New("the request wasn't executed by the runner")
+	}
+	return nil, err
+}
+
+// rewindableBody provides the request's body for every attempt, the request's GetBody is used when present otherwise
+// the body is read into memory. Nil is returned for requests without a body.
+func rewindableBody(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		return req.GetBody, nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}, nil
+}
+
+// discard drains and closes the body of a response that won't be returned
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDiscardBytes))
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,267 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/csueiras/reinforcer/pkg/runner"
+	"github.com/csueiras/reinforcer/pkg/transport"
+	"github.com/slok/goresilience"
+	"github.com/slok/goresilience/retry"
+	"github.com/slok/goresilience/timeout"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingFactory records the names of the runners requested
+type recordingFactory struct {
+	mu    sync.Mutex
+	names []string
+	*runner.Factory
+}
+
+func (f *recordingFactory) GetRunner(name string) goresilience.Runner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.names = append(f.names, name)
+	return f.Factory.GetRunner(name)
+}
+
+// server responds with the given statuses in order, the last one is repeated, and records the bodies received
+type server struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	status := s.statuses[min(len(s.bodies), len(s.statuses)-1)]
+	s.bodies = append(s.bodies, string(b))
+	s.mu.Unlock()
+
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(http.StatusText(status)))
+}
+
+func retrying(times int) *runner.Factory {
+	return runner.NewFactory(retry.NewMiddleware(retry.Config{Times: times, WaitBase: time.Millisecond}))
+}
+
+func newClient(t *testing.T, f transport.RunnerFactory, statuses ...int) (*http.Client, *server, string) {
+	s := &server{statuses: statuses}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return &http.Client{Transport: transport.New(ts.Client().Transport, f)}, s, ts.URL
+}
+
+func TestTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		wantStatus int
+		wantCalls  int
+	}{
+		{
+			name:       "Success",
+			statuses:   []int{http.StatusOK},
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name:       "Retries 5xx",
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK},
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+		},
+		{
+			name:       "Retries 429",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusCreated},
+			wantStatus: http.StatusCreated,
+			wantCalls:  2,
+		},
+		{
+			name:       "Does Not Retry 4xx",
+			statuses:   []int{http.StatusBadRequest, http.StatusOK},
+			wantStatus: http.StatusBadRequest,
+			wantCalls:  1,
+		},
+		{
+			name:       "Retries Exhausted",
+			statuses:   []int{http.StatusBadGateway},
+			wantStatus: http.StatusBadGateway,
+			wantCalls:  3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, s, url := newClient(t, retrying(2), tt.statuses...)
+
+			resp, err := client.Get(url + "/users/1")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.wantStatus, resp.StatusCode)
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusText(tt.wantStatus), string(b))
+			require.Len(t, s.bodies, tt.wantCalls)
+		})
+	}
+}
+
+func TestTransport_RoundTrip_RewindsBody(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}
+
+	t.Run("GetBody", func(t *testing.T) {
+		client, s, url := newClient(t, retrying(2), statuses...)
+		resp, err := client.Post(url+"/users", "text/plain", strings.NewReader("payload"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, []string{"payload", "payload", "payload"}, s.bodies)
+	})
+
+	t.Run("Buffered", func(t *testing.T) {
+		client, s, url := newClient(t, retrying(2), statuses...)
+		// The request's body can't be rewound by the request itself
+		resp, err := client.Post(url+"/users", "text/plain", io.MultiReader(strings.NewReader("pay"), strings.NewReader("load")))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, []string{"payload", "payload", "payload"}, s.bodies)
+	})
+}
+
+func TestTransport_RoundTrip_Errors(t *testing.T) {
+	t.Run("Retries Errors", func(t *testing.T) {
+		errConn := errors.New("connection reset")
+		calls := 0
+		base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return nil, errConn
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		})
+		client := &http.Client{Transport: transport.New(base, retrying(1))}
+		resp, err := client.Get("http://example.com/users/1")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Retries Exhausted", func(t *testing.T) {
+		errConn := errors.New("connection reset")
+		base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errConn
+		})
+		client := &http.Client{Transport: transport.New(base, retrying(1))}
+		_, err := client.Get("http://example.com/users/1")
+		require.ErrorIs(t, err, errConn)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		unblock := make(chan struct{})
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-unblock
+		}))
+		defer ts.Close()
+		defer close(unblock)
+
+		f := runner.NewFactory(timeout.NewMiddleware(timeout.Config{Timeout: 10 * time.Millisecond}))
+		client := &http.Client{Transport: transport.New(ts.Client().Transport, f)}
+		_, err := client.Get(ts.URL)
+		require.Error(t, err)
+	})
+
+	t.Run("Abandoned Attempts", func(t *testing.T) {
+		// The first attempt is given up on by the timeout and finishes while the second one is in-flight
+		delays := []time.Duration{70 * time.Millisecond, 30 * time.Millisecond}
+		var mu sync.Mutex
+		var bodies []*trackedBody
+		base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			attempt := len(bodies)
+			body := &trackedBody{Reader: strings.NewReader(fmt.Sprintf("attempt %d", attempt+1))}
+			bodies = append(bodies, body)
+			mu.Unlock()
+			time.Sleep(delays[attempt])
+			return &http.Response{StatusCode: http.StatusOK, Body: body, Request: req}, nil
+		})
+		f := runner.NewFactory(
+			retry.NewMiddleware(retry.Config{Times: 1, WaitBase: time.Millisecond, DisableBackoff: true}),
+			timeout.NewMiddleware(timeout.Config{Timeout: 50 * time.Millisecond}),
+		)
+		client := &http.Client{Transport: transport.New(base, f)}
+		resp, err := client.Get("http://example.com/users/1")
+		require.NoError(t, err)
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "attempt 2", string(b))
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, bodies, 2)
+		require.True(t, bodies[0].closed.Load())
+		require.False(t, bodies[1].closed.Load())
+	})
+
+	t.Run("Runner Does Not Execute The Request", func(t *testing.T) {
+		f := runner.NewFactory(func(next goresilience.Runner) goresilience.Runner {
+			return goresilience.RunnerFunc(func(ctx context.Context, f goresilience.Func) error {
+				return nil
+			})
+		})
+		client := &http.Client{Transport: transport.New(nil, f)}
+		_, err := client.Get("http://example.com/users/1")
+		require.Error(t, err)
+	})
+}
+
+func TestTransport_WithRetryableResponse(t *testing.T) {
+	s := &server{statuses: []int{http.StatusNotFound, http.StatusServiceUnavailable}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	notFound := func(resp *http.Response) bool {
+		return resp.StatusCode == http.StatusNotFound
+	}
+	client := &http.Client{Transport: transport.New(ts.Client().Transport, retrying(2), transport.WithRetryableResponse(notFound))}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Len(t, s.bodies, 2)
+}
+
+func TestNew(t *testing.T) {
+	require.Panics(t, func() {
+		transport.New(nil, nil)
+	})
+}
+
+// trackedBody is a response body that records whether it was closed
+type trackedBody struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}